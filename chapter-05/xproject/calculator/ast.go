@@ -0,0 +1,45 @@
+package calculator
+
+import "fmt"
+
+// Node is a node of a parsed expression.
+type Node interface {
+	// Pos is the 1-based column of the node's number or operator.
+	Pos() int
+	// String returns the expression with every operation parenthesized.
+	String() string
+}
+
+// Number is a numeric literal, kept as written.
+type Number struct {
+	Column int
+	Text   string
+}
+
+// Unary is a unary minus or plus applied to X.
+type Unary struct {
+	Column int
+	Op     byte
+	X      Node
+}
+
+// Binary is X Op Y, where Op is one of + - * / ^.
+type Binary struct {
+	Column int
+	Op     byte
+	X, Y   Node
+}
+
+func (n *Number) Pos() int { return n.Column }
+func (n *Unary) Pos() int  { return n.Column }
+func (n *Binary) Pos() int { return n.Column }
+
+func (n *Number) String() string { return n.Text }
+
+func (n *Unary) String() string {
+	return fmt.Sprintf("(%c%s)", n.Op, n.X)
+}
+
+func (n *Binary) String() string {
+	return fmt.Sprintf("(%s %c %s)", n.X, n.Op, n.Y)
+}
@@ -0,0 +1,150 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Limits of the big.Int and big.Rat modes, so an input like 9^9^9 fails
+// quickly instead of exhausting memory: powers may have at most maxBits
+// bits and literals like 1e5 an exponent of at most maxExponent.
+const (
+	maxBits     = 1 << 20
+	maxExponent = 1 << 16
+)
+
+// EvalBigInt evaluates expr with arbitrary-precision integers. Division
+// truncates towards zero, as in Go. Numbers must be integers.
+func EvalBigInt(expr string) (*big.Int, error) {
+	n, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate[*big.Int](n, bigIntArith{})
+}
+
+// EvalRat evaluates expr exactly with arbitrary-precision fractions, so
+// 0.1+0.2 is exactly 3/10 and 1/3*3 is exactly 1. Exponents must be
+// integers.
+func EvalRat(expr string) (*big.Rat, error) {
+	n, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate[*big.Rat](n, ratArith{})
+}
+
+// bigIntArith evaluates in *big.Int.
+type bigIntArith struct{}
+
+func (bigIntArith) number(text string) (*big.Int, error) {
+	return parseInteger(text)
+}
+
+func (bigIntArith) neg(x *big.Int) (*big.Int, error) {
+	return new(big.Int).Neg(x), nil
+}
+
+func (bigIntArith) apply(op byte, x, y *big.Int) (*big.Int, error) {
+	switch op {
+	case '+':
+		return new(big.Int).Add(x, y), nil
+	case '-':
+		return new(big.Int).Sub(x, y), nil
+	case '*':
+		return new(big.Int).Mul(x, y), nil
+	case '/':
+		if y.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return new(big.Int).Quo(x, y), nil
+	case '^':
+		if y.Sign() < 0 {
+			return nil, fmt.Errorf("negative exponent: %w", ErrNotInteger)
+		}
+		if err := checkExponent(x, y); err != nil {
+			return nil, err
+		}
+		return new(big.Int).Exp(x, y, nil), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// ratArith evaluates in *big.Rat.
+type ratArith struct{}
+
+func (ratArith) number(text string) (*big.Rat, error) {
+	return parseRat(text)
+}
+
+func (ratArith) neg(x *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Neg(x), nil
+}
+
+func (ratArith) apply(op byte, x, y *big.Rat) (*big.Rat, error) {
+	switch op {
+	case '+':
+		return new(big.Rat).Add(x, y), nil
+	case '-':
+		return new(big.Rat).Sub(x, y), nil
+	case '*':
+		return new(big.Rat).Mul(x, y), nil
+	case '/':
+		if y.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return new(big.Rat).Quo(x, y), nil
+	case '^':
+		if !y.IsInt() {
+			return nil, fmt.Errorf("exponent %s is %w", y.RatString(), ErrNotInteger)
+		}
+		e := new(big.Int).Abs(y.Num())
+		if err := checkExponent(x.Num(), e); err != nil {
+			return nil, err
+		}
+		if err := checkExponent(x.Denom(), e); err != nil {
+			return nil, err
+		}
+		num := new(big.Int).Exp(x.Num(), e, nil)
+		den := new(big.Int).Exp(x.Denom(), e, nil)
+		if y.Sign() < 0 {
+			if num.Sign() == 0 {
+				return nil, ErrDivisionByZero // 0^-1
+			}
+			num, den = den, num
+		}
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// checkExponent rejects powers of base that would have more than maxBits
+// bits. Powers of -1, 0 and 1 are always allowed.
+func checkExponent(base, e *big.Int) error {
+	abs := new(big.Int).Abs(base)
+	if abs.Cmp(big.NewInt(1)) <= 0 {
+		return nil
+	}
+	bits := int64(abs.BitLen() - 1) // the whole part of log2 |base|, at least 1
+	if !e.IsInt64() || e.Int64() > maxBits || bits*e.Int64() > maxBits {
+		return ErrOutOfRange
+	}
+	return nil
+}
+
+// parseRat reads a literal exactly, so 0.1 is 1/10.
+func parseRat(text string) (*big.Rat, error) {
+	// big.Rat would build the full number for an exponent like 1e999999999.
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		var exp int
+		if _, err := fmt.Sscan(strings.TrimPrefix(text[i+1:], "+"), &exp); err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, ErrOutOfRange
+		}
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("malformed number %q", text)
+	}
+	return r, nil
+}
@@ -0,0 +1,64 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvalBigInt(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+		err      error
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808", nil},
+		{"2^100", "1267650600228229401496703205376", nil},
+		{"-(2^64) / 3", "-6148914691236517205", nil},
+		{"(-1)^1000001", "-1", nil},
+		{"1e20", "100000000000000000000", nil},
+		{"0.5", "", ErrNotInteger},
+		{"2^-1", "", ErrNotInteger},
+		{"1 / 0", "", ErrDivisionByZero},
+		{"9^9^9", "", ErrOutOfRange},
+		{"1e100000", "", ErrOutOfRange},
+	}
+	for _, tc := range testCases {
+		actual, err := EvalBigInt(tc.expr)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("EvalBigInt(%q) failed, expected error %v, got %v", tc.expr, tc.err, err)
+			continue
+		}
+		if err == nil && actual.String() != tc.expected {
+			t.Errorf("EvalBigInt(%q) failed, expected %s, got %s", tc.expr, tc.expected, actual)
+		}
+	}
+}
+
+func TestEvalRat(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+		err      error
+	}{
+		{"0.1 + 0.2", "3/10", nil},
+		{"1/3 * 3", "1", nil},
+		{"2*(3+4)/5", "14/5", nil},
+		{"(2/3)^-2", "9/4", nil},
+		{"-1.5e-3", "-3/2000", nil},
+		{"2^64 + 0.5", "36893488147419103233/2", nil},
+		{"2^0.5", "", ErrNotInteger},
+		{"0^-1", "", ErrDivisionByZero},
+		{"1/(1/3 - 1/3)", "", ErrDivisionByZero},
+		{"(1/3)^2000000", "", ErrOutOfRange},
+	}
+	for _, tc := range testCases {
+		actual, err := EvalRat(tc.expr)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("EvalRat(%q) failed, expected error %v, got %v", tc.expr, tc.err, err)
+			continue
+		}
+		if err == nil && actual.RatString() != tc.expected {
+			t.Errorf("EvalRat(%q) failed, expected %s, got %s", tc.expr, tc.expected, actual.RatString())
+		}
+	}
+}
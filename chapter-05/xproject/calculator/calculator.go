@@ -1,11 +1,13 @@
 package calculator
 
-// Add performs addition of two numbers
+// Add performs addition of two numbers. Like Go's + it wraps around on
+// overflow; AddChecked reports it instead.
 func Add(a int, b int) int {
 	return a + b
 }
 
-// multiply performs multiplication of two numbers
+// Multiply performs multiplication of two numbers. Like Go's * it wraps
+// around on overflow; MultiplyChecked reports it instead.
 func Multiply(a int, b int) int {
 	return a * b
 }
@@ -0,0 +1,144 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var (
+	// ErrOverflow is returned when a result does not fit in an int.
+	ErrOverflow = errors.New("integer overflow")
+	// ErrNotInteger is returned for fractional numbers and exponents where
+	// only integers are allowed.
+	ErrNotInteger = errors.New("not an integer")
+)
+
+// AddChecked returns a + b, or ErrOverflow if the sum does not fit in an int.
+func AddChecked(a int, b int) (int, error) {
+	if (b > 0 && a > math.MaxInt-b) || (b < 0 && a < math.MinInt-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+// SubtractChecked returns a - b, or ErrOverflow if the difference does not
+// fit in an int.
+func SubtractChecked(a int, b int) (int, error) {
+	if (b < 0 && a > math.MaxInt+b) || (b > 0 && a < math.MinInt+b) {
+		return 0, ErrOverflow
+	}
+	return a - b, nil
+}
+
+// MultiplyChecked returns a * b, or ErrOverflow if the product does not fit
+// in an int.
+func MultiplyChecked(a int, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	// MinInt * -1 wraps around to MinInt, which the division check
+	// below cannot see.
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, ErrOverflow
+	}
+	c := a * b
+	if c/b != a {
+		return 0, ErrOverflow
+	}
+	return c, nil
+}
+
+// DivideChecked returns a / b truncated towards zero, ErrDivisionByZero if
+// b is 0, or ErrOverflow for MinInt / -1.
+func DivideChecked(a int, b int) (int, error) {
+	switch {
+	case b == 0:
+		return 0, ErrDivisionByZero
+	case a == math.MinInt && b == -1:
+		return 0, ErrOverflow
+	}
+	return a / b, nil
+}
+
+// PowerChecked returns a raised to the non-negative power b, or ErrOverflow
+// if the result does not fit in an int.
+func PowerChecked(a int, b int) (int, error) {
+	if b < 0 {
+		return 0, fmt.Errorf("negative exponent: %w", ErrNotInteger)
+	}
+	result := 1
+	for b > 0 {
+		var err error
+		if b&1 == 1 {
+			if result, err = MultiplyChecked(result, a); err != nil {
+				return 0, err
+			}
+		}
+		b >>= 1
+		if b > 0 {
+			if a, err = MultiplyChecked(a, a); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// EvalInt evaluates expr in int arithmetic: every operation is checked for
+// overflow and division truncates towards zero, as in Go. Numbers must be
+// integers, though they may be written as 1e3.
+func EvalInt(expr string) (int, error) {
+	n, err := Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	return evaluate[int](n, intArith{})
+}
+
+// intArith evaluates in int with overflow checks.
+type intArith struct{}
+
+func (intArith) number(text string) (int, error) {
+	i, err := parseInteger(text)
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() || i.Int64() > math.MaxInt || i.Int64() < math.MinInt {
+		return 0, ErrOverflow
+	}
+	return int(i.Int64()), nil
+}
+
+func (intArith) neg(x int) (int, error) {
+	return SubtractChecked(0, x)
+}
+
+func (intArith) apply(op byte, x, y int) (int, error) {
+	switch op {
+	case '+':
+		return AddChecked(x, y)
+	case '-':
+		return SubtractChecked(x, y)
+	case '*':
+		return MultiplyChecked(x, y)
+	case '/':
+		return DivideChecked(x, y)
+	case '^':
+		return PowerChecked(x, y)
+	}
+	return 0, fmt.Errorf("unknown operator %q", op)
+}
+
+// parseInteger reads a literal that must denote an integer.
+func parseInteger(text string) (*big.Int, error) {
+	r, err := parseRat(text)
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s is %w", text, ErrNotInteger)
+	}
+	return r.Num(), nil
+}
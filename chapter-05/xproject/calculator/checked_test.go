@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCheckedArithmetic(t *testing.T) {
+	testCases := []struct {
+		name     string
+		op       func(int, int) (int, error)
+		a        int
+		b        int
+		expected int
+		err      error
+	}{
+		{"add", AddChecked, 10, 5, 15, nil},
+		{"add max", AddChecked, math.MaxInt - 1, 1, math.MaxInt, nil},
+		{"add max overflow", AddChecked, math.MaxInt, 1, 0, ErrOverflow},
+		{"add min", AddChecked, math.MinInt + 1, -1, math.MinInt, nil},
+		{"add min overflow", AddChecked, math.MinInt, -1, 0, ErrOverflow},
+		{"add opposite signs", AddChecked, math.MaxInt, math.MinInt, -1, nil},
+		{"subtract", SubtractChecked, 10, 15, -5, nil},
+		{"subtract min overflow", SubtractChecked, math.MinInt, 1, 0, ErrOverflow},
+		{"subtract max overflow", SubtractChecked, 0, math.MinInt, 0, ErrOverflow},
+		{"subtract to min", SubtractChecked, -1, math.MaxInt, math.MinInt, nil},
+		{"multiply", MultiplyChecked, -10, 5, -50, nil},
+		{"multiply zero", MultiplyChecked, math.MinInt, 0, 0, nil},
+		{"multiply max", MultiplyChecked, math.MaxInt, -1, -math.MaxInt, nil},
+		{"multiply min by -1", MultiplyChecked, math.MinInt, -1, 0, ErrOverflow},
+		{"multiply -1 by min", MultiplyChecked, -1, math.MinInt, 0, ErrOverflow},
+		{"multiply overflow", MultiplyChecked, 1 << 32, 1 << 31, 0, ErrOverflow},
+		{"multiply to min", MultiplyChecked, 1 << 62, -2, math.MinInt, nil},
+		{"divide", DivideChecked, -7, 2, -3, nil},
+		{"divide by zero", DivideChecked, 1, 0, 0, ErrDivisionByZero},
+		{"divide min by -1", DivideChecked, math.MinInt, -1, 0, ErrOverflow},
+		{"power", PowerChecked, 3, 4, 81, nil},
+		{"power zero", PowerChecked, 0, 0, 1, nil},
+		{"power max", PowerChecked, 2, 62, 1 << 62, nil},
+		{"power overflow", PowerChecked, 2, 63, 0, ErrOverflow},
+		{"power negative base", PowerChecked, -2, 63, math.MinInt, nil},
+		{"power negative exponent", PowerChecked, 2, -1, 0, ErrNotInteger},
+	}
+	for _, tc := range testCases {
+		actual, err := tc.op(tc.a, tc.b)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s(%d,%d) failed, expected error %v, got %v", tc.name, tc.a, tc.b, tc.err, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s(%d,%d) failed, expected %d, got %d", tc.name, tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestEvalInt(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected int
+		err      error
+	}{
+		{"2*(3+4)/5", 2, nil},
+		{"-7/2", -3, nil},
+		{"1e3 + 1", 1001, nil},
+		{"9223372036854775807", math.MaxInt, nil},
+		{"-9223372036854775807 - 1", math.MinInt, nil},
+		{"9223372036854775807 + 1", 0, ErrOverflow},
+		{"9223372036854775808", 0, ErrOverflow},
+		{"-(-9223372036854775807 - 1)", 0, ErrOverflow},
+		{"3037000500 * 3037000500", 0, ErrOverflow},
+		{"1.5 * 2", 0, ErrNotInteger},
+		{"2^-1", 0, ErrNotInteger},
+		{"5 / (3 - 3)", 0, ErrDivisionByZero},
+	}
+	for _, tc := range testCases {
+		actual, err := EvalInt(tc.expr)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("EvalInt(%q) failed, expected error %v, got %v", tc.expr, tc.err, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("EvalInt(%q) failed, expected %d, got %d", tc.expr, tc.expected, actual)
+		}
+	}
+}
@@ -1 +1,6 @@
+// Package calculator provides integer helpers and an expression evaluator.
+//
+// Eval accepts numbers (12, 0.5, .5, 1e3), the binary operators + - * / and
+// ^, unary minus and plus, and parentheses. ^ binds tightest and groups
+// right to left, so 2^3^2 is 512 and -2^2 is -4.
 package calculator
@@ -0,0 +1,137 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	// ErrDivisionByZero is returned, wrapped in an *EvalError, for x/0.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrNotReal is returned for results such as (-8)^0.5 that are not
+	// real numbers.
+	ErrNotReal = errors.New("result is not a real number")
+	// ErrOutOfRange is returned for results too large for a float64.
+	ErrOutOfRange = errors.New("result out of range")
+)
+
+// EvalError reports an operation that could not be computed. Pos is the
+// 1-based column of its operator.
+type EvalError struct {
+	Pos int
+	Err error
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("column %d: %v", e.Pos, e.Err)
+}
+
+func (e *EvalError) Unwrap() error {
+	return e.Err
+}
+
+// Eval parses and evaluates an expression such as "2*(3+4)/5" using
+// float64 arithmetic.
+func Eval(expr string) (float64, error) {
+	n, err := Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	return EvalNode(n)
+}
+
+// EvalNode evaluates a parsed expression using float64 arithmetic.
+func EvalNode(n Node) (float64, error) {
+	return evaluate[float64](n, floatArith{})
+}
+
+// arithmetic is a number type expressions can be evaluated in. Its errors
+// are wrapped in an *EvalError giving the column of the literal or
+// operator.
+type arithmetic[T any] interface {
+	number(text string) (T, error)
+	neg(x T) (T, error)
+	apply(op byte, x, y T) (T, error)
+}
+
+func evaluate[T any](n Node, a arithmetic[T]) (T, error) {
+	var (
+		zero T
+		r    T
+		err  error
+	)
+	switch n := n.(type) {
+	case *Number:
+		r, err = a.number(n.Text)
+	case *Unary:
+		x, xErr := evaluate(n.X, a)
+		if xErr != nil || n.Op == '+' {
+			return x, xErr
+		}
+		r, err = a.neg(x)
+	case *Binary:
+		x, xErr := evaluate(n.X, a)
+		if xErr != nil {
+			return zero, xErr
+		}
+		y, yErr := evaluate(n.Y, a)
+		if yErr != nil {
+			return zero, yErr
+		}
+		r, err = a.apply(n.Op, x, y)
+	default:
+		return zero, fmt.Errorf("calculator: unknown node %T", n)
+	}
+	if err != nil {
+		return zero, &EvalError{Pos: n.Pos(), Err: err}
+	}
+	return r, nil
+}
+
+// floatArith evaluates in float64.
+type floatArith struct{}
+
+func (floatArith) number(text string) (float64, error) {
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	return v, nil
+}
+
+func (floatArith) neg(x float64) (float64, error) {
+	return -x, nil
+}
+
+func (floatArith) apply(op byte, x, y float64) (float64, error) {
+	var r float64
+	switch op {
+	case '+':
+		r = x + y
+	case '-':
+		r = x - y
+	case '*':
+		r = x * y
+	case '/':
+		if y == 0 {
+			return 0, ErrDivisionByZero
+		}
+		r = x / y
+	case '^':
+		r = math.Pow(x, y)
+	default:
+		return 0, fmt.Errorf("unknown operator %q", op)
+	}
+	switch {
+	case math.IsNaN(r):
+		return 0, ErrNotReal
+	case math.IsInf(r, 0):
+		if op == '^' && x == 0 {
+			return 0, ErrDivisionByZero // 0^-1
+		}
+		return 0, ErrOutOfRange
+	}
+	return r, nil
+}
@@ -0,0 +1,62 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected float64
+	}{
+		{"2*(3+4)/5", 2.8},
+		{"1 + 2 * 3", 7},
+		{"10 - 4 - 3", 3},
+		{"2^3^2", 512},
+		{"-2^2", -4},
+		{"(-2)^2", 4},
+		{"2^-1", 0.5},
+		{"-(-3)", 3},
+		{"1.5e3 / 3", 500},
+		{".1 + .2", 0.1 + 0.2},
+		{"8 / 2 / 2", 2},
+	}
+	for _, tc := range testCases {
+		actual, err := Eval(tc.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %v", tc.expr, err)
+			continue
+		}
+		if math.Abs(actual-tc.expected) > 1e-12 {
+			t.Errorf("Eval(%q) failed, expected %v, got %v", tc.expr, tc.expected, actual)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected error
+		pos      int
+	}{
+		{"1 / 0", ErrDivisionByZero, 3},
+		{"1 / (2 - 2)", ErrDivisionByZero, 3},
+		{"0 ^ -1", ErrDivisionByZero, 3},
+		{"(-8) ^ 0.5", ErrNotReal, 6},
+		{"10 ^ 400", ErrOutOfRange, 4},
+		{"1e999", ErrOutOfRange, 1},
+	}
+	for _, tc := range testCases {
+		_, err := Eval(tc.expr)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("Eval(%q) failed, expected %v, got %v", tc.expr, tc.expected, err)
+			continue
+		}
+		var evalErr *EvalError
+		if !errors.As(err, &evalErr) || evalErr.Pos != tc.pos {
+			t.Errorf("Eval(%q) failed, expected the error at column %d, got %v", tc.expr, tc.pos, err)
+		}
+	}
+}
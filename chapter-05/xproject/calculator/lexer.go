@@ -0,0 +1,120 @@
+package calculator
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a token read from an expression.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokPlus
+	tokMinus
+	tokStar
+	tokSlash
+	tokCaret
+	tokLParen
+	tokRParen
+)
+
+// token is one lexical element of an expression. Pos is the 1-based column
+// of its first character, counted in runes.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var operators = map[byte]tokenKind{
+	'+': tokPlus,
+	'-': tokMinus,
+	'*': tokStar,
+	'/': tokSlash,
+	'^': tokCaret,
+	'(': tokLParen,
+	')': tokRParen,
+}
+
+// tokenize splits expr into tokens, ending with a tokEOF token.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	col := 1
+	for i := 0; i < len(expr); col++ {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || c == '.':
+			n, err := scanNumber(expr, i, col)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: expr[i:n], pos: col})
+			// Numbers are ASCII, so each byte is one column.
+			col += n - i - 1
+			i = n
+		default:
+			kind, ok := operators[c]
+			if !ok {
+				r, _ := utf8.DecodeRuneInString(expr[i:])
+				return nil, &SyntaxError{Pos: col, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: kind, text: string(c), pos: col})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: col}), nil
+}
+
+// scanNumber returns the end of the number starting at expr[start], which
+// is at column col: digits with an optional fraction and exponent, as in
+// 12, 0.5, .5 or 1.5e-3.
+func scanNumber(expr string, start, col int) (int, error) {
+	i := start
+	digits := 0
+	for i < len(expr) && isDigit(expr[i]) {
+		i++
+		digits++
+	}
+	if i < len(expr) && expr[i] == '.' {
+		i++
+		for i < len(expr) && isDigit(expr[i]) {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, &SyntaxError{Pos: col, Msg: "malformed number"}
+	}
+	if i < len(expr) && (expr[i] == 'e' || expr[i] == 'E') {
+		j := i + 1
+		if j < len(expr) && (expr[j] == '+' || expr[j] == '-') {
+			j++
+		}
+		if j >= len(expr) || !isDigit(expr[j]) {
+			return 0, &SyntaxError{Pos: col, Msg: "malformed exponent in number"}
+		}
+		for j < len(expr) && isDigit(expr[j]) {
+			j++
+		}
+		i = j
+	}
+	if i < len(expr) && (expr[i] == '.' || isDigit(expr[i])) {
+		return 0, &SyntaxError{Pos: col, Msg: "malformed number"}
+	}
+	return i, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,145 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError reports a malformed expression. Pos is the 1-based column
+// where the problem was found.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Pos, e.Msg)
+}
+
+// Caret returns expr with a second line pointing at the error's column,
+// for showing the error under the input.
+func (e *SyntaxError) Caret(expr string) string {
+	return expr + "\n" + strings.Repeat(" ", e.Pos-1) + "^"
+}
+
+// binaryOps lists the binary operators with their precedence; ^ binds
+// tightest and is the only right-associative one.
+var binaryOps = map[tokenKind]struct {
+	op         byte
+	prec       int
+	rightAssoc bool
+}{
+	tokPlus:  {'+', 1, false},
+	tokMinus: {'-', 1, false},
+	tokStar:  {'*', 2, false},
+	tokSlash: {'/', 2, false},
+	tokCaret: {'^', 4, true},
+}
+
+// unaryPrec is the precedence of unary minus: it binds tighter than * and
+// / but looser than ^, so -2^2 is -(2^2).
+const unaryPrec = 3
+
+// Parse parses expr into an AST.
+func Parse(expr string) (Node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.expr(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return n, nil
+}
+
+// parser is a precedence-climbing parser over a token list.
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokRParen {
+		return &SyntaxError{Pos: t.pos, Msg: "unmatched )"}
+	}
+	return &SyntaxError{Pos: t.pos, Msg: "unexpected " + t.String()}
+}
+
+// expr parses operations whose operators have at least precedence minPrec.
+func (p *parser) expr(minPrec int) (Node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		op, ok := binaryOps[t.kind]
+		if !ok || op.prec < minPrec {
+			return left, nil
+		}
+		p.advance()
+		next := op.prec + 1
+		if op.rightAssoc {
+			next = op.prec
+		}
+		right, err := p.expr(next)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Column: t.pos, Op: op.op, X: left, Y: right}
+	}
+}
+
+func (p *parser) unary() (Node, error) {
+	t := p.peek()
+	if t.kind != tokMinus && t.kind != tokPlus {
+		return p.primary()
+	}
+	p.advance()
+	x, err := p.expr(unaryPrec)
+	if err != nil {
+		return nil, err
+	}
+	return &Unary{Column: t.pos, Op: t.text[0], X: x}, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokNumber:
+		return &Number{Column: t.pos, Text: t.text}, nil
+	case tokLParen:
+		n, err := p.expr(1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokRParen {
+			if closing.kind == tokEOF {
+				return nil, &SyntaxError{Pos: t.pos, Msg: "unclosed ("}
+			}
+			return nil, p.unexpected(closing)
+		}
+		p.advance()
+		return n, nil
+	case tokEOF:
+		return nil, &SyntaxError{Pos: t.pos, Msg: "unexpected end of expression, expected a number or ("}
+	}
+	return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s, expected a number or (", t)}
+}
@@ -0,0 +1,78 @@
+package calculator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"2*(3+4)/5", "((2 * (3 + 4)) / 5)"},
+		{"10 - 4 - 3", "((10 - 4) - 3)"},
+		{"2^3^2", "(2 ^ (3 ^ 2))"},
+		{"-2^2", "(-(2 ^ 2))"},
+		{"2^-1", "(2 ^ (-1))"},
+		{"-3 * -(4)", "((-3) * (-4))"},
+		{"+.5e1", "(+.5e1)"},
+		{" ( ( 7 ) ) ", "7"},
+	}
+	for _, tc := range testCases {
+		n, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tc.expr, err)
+			continue
+		}
+		if actual := n.String(); actual != tc.expected {
+			t.Errorf("Parse(%q) failed, expected %s, got %s", tc.expr, tc.expected, actual)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{"", "syntax error at column 1: unexpected end of expression, expected a number or ("},
+		{"2 +", "syntax error at column 4: unexpected end of expression, expected a number or ("},
+		{"2 * x", `syntax error at column 5: unexpected character 'x'`},
+		{"2×3", `syntax error at column 2: unexpected character '×'`},
+		{"π² + 1", `syntax error at column 1: unexpected character 'π'`},
+		{"(1 + 2", "syntax error at column 1: unclosed ("},
+		{"1 + 2)", "syntax error at column 6: unmatched )"},
+		{"2 3", `syntax error at column 3: unexpected "3"`},
+		{"4 * / 2", `syntax error at column 5: unexpected "/", expected a number or (`},
+		{"1.2.3", "syntax error at column 1: malformed number"},
+		{"1e+", "syntax error at column 1: malformed exponent in number"},
+		{"()", `syntax error at column 2: unexpected ")", expected a number or (`},
+	}
+	for _, tc := range testCases {
+		_, err := Parse(tc.expr)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) failed, expected a *SyntaxError, got %v", tc.expr, err)
+			continue
+		}
+		if actual := err.Error(); actual != tc.expected {
+			t.Errorf("Parse(%q) failed, expected %q, got %q", tc.expr, tc.expected, actual)
+		}
+	}
+
+	err := &SyntaxError{Pos: 5, Msg: "unexpected character 'x'"}
+	if actual, expected := err.Caret("2 * x"), "2 * x\n    ^"; actual != expected {
+		t.Errorf("Caret failed, expected %q, got %q", expected, actual)
+	}
+
+	_, parseErr := Parse("2×3")
+	var syntaxErr *SyntaxError
+	if !errors.As(parseErr, &syntaxErr) {
+		t.Fatalf("Parse(%q) failed, expected a *SyntaxError, got %v", "2×3", parseErr)
+	}
+	if actual, expected := syntaxErr.Caret("2×3"), "2×3\n ^"; actual != expected {
+		t.Errorf("Caret failed, expected %q, got %q", expected, actual)
+	}
+}
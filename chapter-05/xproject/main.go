@@ -1,15 +1,89 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"math/big"
+	"os"
+	"strings"
 	"xproject/calculator"
 	"xproject/greeting"
 )
 
 func main() {
+	mode := flag.String("mode", "float", "numeric mode: float, int (checked for overflow), big (arbitrary-precision integers) or rat (exact fractions)")
+	flag.Parse()
+
+	eval, ok := evaluators[*mode]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown mode %q; use float, int, big or rat\n", *mode)
+		os.Exit(2)
+	}
+
 	greeting.SayHello("Alice")
 	sum := calculator.Add(10, 5)
 	fmt.Println("Sum:", sum)
 	product := calculator.Multiply(4, 3)
 	fmt.Println("Product:", product)
+
+	exprs := flag.Args()
+	if len(exprs) == 0 {
+		exprs = []string{"2*(3+4)/5"}
+	}
+	status := 0
+	for _, expr := range exprs {
+		result, err := eval(expr)
+		if err != nil {
+			var syntaxErr *calculator.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				fmt.Fprintln(os.Stderr, syntaxErr.Caret(expr))
+			}
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s = %s\n", expr, result)
+	}
+	os.Exit(status)
+}
+
+// evaluators evaluate an expression in each numeric mode and format the
+// result.
+var evaluators = map[string]func(string) (string, error){
+	"float": func(expr string) (string, error) {
+		r, err := calculator.Eval(expr)
+		return fmt.Sprintf("%g", r), err
+	},
+	"int": func(expr string) (string, error) {
+		r, err := calculator.EvalInt(expr)
+		return fmt.Sprint(r), err
+	},
+	"big": func(expr string) (string, error) {
+		r, err := calculator.EvalBigInt(expr)
+		if err != nil {
+			return "", err
+		}
+		return r.String(), nil
+	},
+	"rat": func(expr string) (string, error) {
+		r, err := calculator.EvalRat(expr)
+		if err != nil {
+			return "", err
+		}
+		if r.IsInt() {
+			return r.RatString(), nil
+		}
+		return fmt.Sprintf("%s (%s)", r.RatString(), decimal(r, 10)), nil
+	},
+}
+
+// decimal writes r with at most digits decimals, ending in "..." if more
+// would follow.
+func decimal(r *big.Rat, digits int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	if !new(big.Rat).Mul(r, new(big.Rat).SetInt(scale)).IsInt() {
+		return r.FloatString(digits) + "..."
+	}
+	return strings.TrimRight(strings.TrimRight(r.FloatString(digits), "0"), ".")
 }
@@ -0,0 +1,584 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todolist/todo"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0
+	ExitError = 1 // the command failed, e.g. an unknown task ID or an I/O error
+	ExitUsage = 2 // the command line was invalid
+)
+
+// usageError marks errors caused by a malformed command line.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// App runs todolist commands against a task file, either one command per
+// process (Run) or interactively (Shell). Both go through Dispatch.
+type App struct {
+	File string
+	In   io.Reader
+	Out  io.Writer
+	Err  io.Writer
+	Now  func() time.Time
+
+	list    *todo.List
+	inShell bool
+}
+
+// command is one entry in the dispatch table. Commands that change the list
+// set mutates so the file is saved after they run.
+type command struct {
+	usage   string
+	mutates bool
+	run     func(a *App, args []string) error
+}
+
+var commands map[string]*command
+
+// aliases maps alternative names onto entries in commands.
+var aliases = map[string]string{
+	"list":       "ls",
+	"complete":   "done",
+	"uncomplete": "undone",
+	"delete":     "rm",
+}
+
+func init() {
+	commands = map[string]*command{
+		"add":      {"add <description> [--due DATE] [--priority P] [--tag T]... [--repeat RULE] [--note TEXT]", true, (*App).add},
+		"ls":       {"ls [--tag T] [--priority P] [--overdue] [--open] [--done] [--due-by DATE] [--sort KEY] [--json] [words]", false, (*App).ls},
+		"agenda":   {"agenda [--json]", false, (*App).agenda},
+		"done":     {"done <id>...", true, (*App).done},
+		"undone":   {"undone <id>...", true, (*App).undone},
+		"rm":       {"rm <id>...", true, (*App).rm},
+		"edit":     {"edit <id> <description>", true, (*App).edit},
+		"due":      {"due <id> <DATE|none>", true, (*App).due},
+		"priority": {"priority <id> <low|medium|high|none>", true, (*App).priority},
+		"tag":      {"tag <id> <tag>...", true, (*App).tag},
+		"untag":    {"untag <id> <tag>...", true, (*App).untag},
+		"note":     {"note <id> <text>", true, (*App).note},
+		"repeat":   {"repeat <id> <RULE|none>", true, (*App).repeat},
+		"import":   {"import <file.ics|file.md>", true, (*App).importFile},
+		"export":   {"export <file.ics|file.md>", false, (*App).exportFile},
+		"undo":     {"undo", true, (*App).undo},
+		"redo":     {"redo", true, (*App).redo},
+		"help":     {"help", false, (*App).help},
+	}
+}
+
+// Run executes a single command given as command-line arguments and returns
+// the process exit code. With no arguments, or with "shell", it starts the
+// interactive shell instead.
+func (a *App) Run(args []string) int {
+	if len(args) == 0 || args[0] == "shell" {
+		return a.Shell()
+	}
+	if err := a.load(); err != nil {
+		fmt.Fprintf(a.Err, "Error loading tasks from file: %v\n", err)
+		return ExitError
+	}
+	return a.exitCode(a.Dispatch(args))
+}
+
+// Shell reads commands from a.In, one per line, until "exit", "quit" or end
+// of input. Undo and redo work across the commands of a session.
+func (a *App) Shell() int {
+	if err := a.load(); err != nil {
+		fmt.Fprintf(a.Err, "Error loading tasks from file: %v\n", err)
+		return ExitError
+	}
+	a.inShell = true
+	defer func() { a.inShell = false }()
+
+	scanner := bufio.NewScanner(a.In)
+	for {
+		fmt.Fprint(a.Out, "Enter command (add/ls/agenda/done/undone/edit/rm/due/priority/tag/untag/note/repeat/import/export/undo/redo/help/exit): ")
+		if !scanner.Scan() {
+			fmt.Fprintln(a.Out)
+			return ExitOK
+		}
+		args, err := SplitArgs(scanner.Text())
+		if err != nil {
+			fmt.Fprintln(a.Err, err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			fmt.Fprintln(a.Out, "Exiting the application")
+			return ExitOK
+		}
+		if err := a.Dispatch(args); err != nil {
+			fmt.Fprintln(a.Err, err)
+		}
+	}
+}
+
+// Dispatch runs one command against the loaded list and saves the list if
+// the command changed it.
+func (a *App) Dispatch(args []string) error {
+	name := args[0]
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		return usagef("unknown command %q, run \"help\" for a list of commands", args[0])
+	}
+	err := cmd.run(a, args[1:])
+	var ue *usageError
+	if errors.As(err, &ue) && !strings.Contains(ue.msg, "usage:") {
+		ue.msg += "\nusage: " + cmd.usage
+	}
+	// Save even after a failure other than a usage error: a command on
+	// several IDs may have applied some of them before stopping.
+	if cmd.mutates && !errors.As(err, &ue) {
+		if saveErr := a.list.Save(a.File); saveErr != nil && err == nil {
+			err = fmt.Errorf("saving tasks to file: %v", saveErr)
+		}
+	}
+	return err
+}
+
+func (a *App) load() error {
+	if a.list != nil {
+		return nil
+	}
+	list, err := todo.LoadList(a.File)
+	if err != nil {
+		return err
+	}
+	a.list = list
+	return nil
+}
+
+func (a *App) exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	fmt.Fprintf(a.Err, "Error: %v\n", err)
+	var ue *usageError
+	if errors.As(err, &ue) {
+		return ExitUsage
+	}
+	return ExitError
+}
+
+func (a *App) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+func (a *App) add(args []string) error {
+	var due, priority, repeat, note string
+	var tags []string
+	fs := newFlagSet("add")
+	fs.StringVar(&due, "due", "", "due date")
+	fs.StringVar(&priority, "priority", "", "priority")
+	fs.Func("tag", "tag, may be repeated", func(s string) error {
+		tags = append(tags, s)
+		return nil
+	})
+	fs.StringVar(&repeat, "repeat", "", "recurrence rule")
+	fs.StringVar(&note, "note", "", "notes")
+	words, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return usagef("description required")
+	}
+
+	task := todo.Task{Description: strings.Join(words, " "), Tags: tags, Notes: strings.TrimSpace(note)}
+	if task.Due, err = todo.ParseDate(due, a.now()); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if task.Priority, err = todo.ParsePriority(priority); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if task.Recurrence, err = todo.ParseRecurrence(repeat); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	added := a.list.AddTask(task)
+	fmt.Fprintf(a.Out, "Task %d added!\n", added.ID)
+	return nil
+}
+
+func (a *App) ls(args []string) error {
+	var filter todo.Filter
+	var priority, dueBy string
+	var asJSON bool
+
+	fs := newFlagSet("ls")
+	fs.StringVar(&filter.Tag, "tag", "", "only tasks with this tag")
+	fs.StringVar(&priority, "priority", "", "only tasks with this priority")
+	fs.BoolVar(&filter.Overdue, "overdue", false, "only overdue tasks")
+	fs.BoolVar(&filter.HideDone, "open", false, "hide completed tasks")
+	fs.BoolVar(&filter.OnlyDone, "done", false, "only completed tasks")
+	fs.StringVar(&dueBy, "due-by", "", "only tasks due on or before this date")
+	fs.StringVar(&filter.SortBy, "sort", "id", "sort by id, due, priority or description")
+	fs.BoolVar(&asJSON, "json", false, "print JSON")
+	words, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	filter.Description = strings.Join(words, " ")
+	if filter.Priority, err = todo.ParsePriority(priority); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if filter.DueBy, err = todo.ParseDate(dueBy, a.now()); err != nil {
+		return &usageError{msg: err.Error()}
+	}
+
+	matched, err := todo.FilterTasks(a.list.Tasks, filter, a.now())
+	if err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	if asJSON {
+		return a.writeJSON(toJSON(matched))
+	}
+	todo.WriteTasks(a.Out, matched)
+	return nil
+}
+
+func (a *App) agenda(args []string) error {
+	var asJSON bool
+	fs := newFlagSet("agenda")
+	fs.BoolVar(&asJSON, "json", false, "print JSON")
+	if rest, err := parseInterspersed(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	if !asJSON {
+		todo.WriteAgenda(a.Out, a.list.Tasks, a.now())
+		return nil
+	}
+	type group struct {
+		Title string     `json:"title"`
+		Tasks []taskJSON `json:"tasks"`
+	}
+	groups := []group{}
+	for _, g := range todo.Agenda(a.list.Tasks, a.now()) {
+		groups = append(groups, group{Title: g.Title, Tasks: toJSON(g.Tasks)})
+	}
+	return a.writeJSON(groups)
+}
+
+func (a *App) done(args []string) error {
+	return a.eachID(args, "Task %d marked as complete\n", func(id int) error {
+		next, err := a.list.Complete(id)
+		if err == nil && next != nil {
+			fmt.Fprintf(a.Out, "Next occurrence: %s\n", todo.FormatTask(*next))
+		}
+		return err
+	})
+}
+
+func (a *App) undone(args []string) error {
+	return a.eachID(args, "Task %d marked as not complete\n", a.list.Uncomplete)
+}
+
+func (a *App) rm(args []string) error {
+	return a.eachID(args, "Task %d deleted\n", a.list.Delete)
+}
+
+func (a *App) edit(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		if rest == "" {
+			return usagef("description required")
+		}
+		return a.list.Edit(id, rest)
+	})
+}
+
+func (a *App) due(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		if rest == "" {
+			return usagef("date required")
+		}
+		due, err := todo.ParseDate(rest, a.now())
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		return a.list.SetDue(id, due)
+	})
+}
+
+func (a *App) priority(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		p, err := todo.ParsePriority(rest)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		return a.list.SetPriority(id, p)
+	})
+}
+
+func (a *App) tag(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		return a.list.AddTags(id, strings.Fields(rest)...)
+	})
+}
+
+func (a *App) untag(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		return a.list.RemoveTags(id, strings.Fields(rest)...)
+	})
+}
+
+func (a *App) note(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		return a.list.SetNotes(id, rest)
+	})
+}
+
+func (a *App) repeat(args []string) error {
+	return a.withID(args, func(id int, rest string) error {
+		r, err := todo.ParseRecurrence(rest)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		return a.list.SetRecurrence(id, r)
+	})
+}
+
+func (a *App) importFile(args []string) error {
+	if len(args) != 1 {
+		return usagef("exactly one file name required")
+	}
+	imported, err := todo.ImportFile(args[0])
+	if err != nil {
+		return err
+	}
+	added := a.list.Import(imported)
+	fmt.Fprintf(a.Out, "Imported %d tasks\n", len(added))
+	return nil
+}
+
+func (a *App) exportFile(args []string) error {
+	if len(args) != 1 {
+		return usagef("exactly one file name required")
+	}
+	if err := todo.ExportFile(a.list.Tasks, args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "Exported %d tasks to %s\n", len(a.list.Tasks), args[0])
+	return nil
+}
+
+// undo and redo only make sense within a shell session: the operation log
+// lives in memory and is gone once a single command has run.
+func (a *App) undo(args []string) error {
+	if !a.inShell {
+		return usagef("undo is only available in the shell (todolist shell)")
+	}
+	name, err := a.list.Undo()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "Undid %s\n", name)
+	return nil
+}
+
+func (a *App) redo(args []string) error {
+	if !a.inShell {
+		return usagef("redo is only available in the shell (todolist shell)")
+	}
+	name, err := a.list.Redo()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "Redid %s\n", name)
+	return nil
+}
+
+func (a *App) help(args []string) error {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(a.Out, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(a.Out, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(a.Out, "  shell")
+	fmt.Fprintln(a.Out, "Aliases: list=ls, complete=done, uncomplete=undone, delete=rm")
+	return nil
+}
+
+// withID parses "<id> <rest...>" and calls fn with the ID and the remaining
+// arguments joined by spaces.
+func (a *App) withID(args []string, fn func(id int, rest string) error) error {
+	if len(args) == 0 {
+		return usagef("task ID required")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return usagef("invalid task id %q", args[0])
+	}
+	if err := fn(id, strings.TrimSpace(strings.Join(args[1:], " "))); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.Out, "Task updated")
+	return nil
+}
+
+// eachID applies fn to every ID in args, stopping at the first failure.
+func (a *App) eachID(args []string, message string, fn func(id int) error) error {
+	if len(args) == 0 {
+		return usagef("task ID required")
+	}
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return usagef("invalid task id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, message, id)
+	}
+	return nil
+}
+
+func (a *App) writeJSON(v any) error {
+	enc := json.NewEncoder(a.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// taskJSON is the shape of a task in --json output.
+type taskJSON struct {
+	ID          int      `json:"id"`
+	Description string   `json:"description"`
+	Completed   bool     `json:"completed"`
+	Due         string   `json:"due,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	Repeat      string   `json:"repeat,omitempty"`
+}
+
+func toJSON(tasks []todo.Task) []taskJSON {
+	result := []taskJSON{}
+	for _, t := range tasks {
+		j := taskJSON{
+			ID:          t.ID,
+			Description: t.Description,
+			Completed:   t.Completed,
+			Tags:        t.Tags,
+			Notes:       t.Notes,
+			Repeat:      t.Recurrence.String(),
+		}
+		if t.HasDue() {
+			j.Due = t.Due.Format(todo.DateLayout)
+		}
+		if t.Priority != todo.PriorityNone {
+			j.Priority = t.Priority.String()
+		}
+		result = append(result, j)
+	}
+	return result
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, e.g. `add "Pay rent" --due tomorrow`. A "--"
+// argument ends flag parsing.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usagef("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// SplitArgs splits a shell line into arguments. Words are separated by
+// spaces; single or double quotes group words and a backslash escapes the
+// next character.
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run executes one subcommand in a fresh App, as a separate process would.
+func run(t *testing.T, file string, args ...string) (int, string, string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	app := &App{
+		File: file,
+		Out:  &out,
+		Err:  &errOut,
+		Now:  func() time.Time { return time.Date(2024, 10, 15, 9, 0, 0, 0, time.Local) },
+	}
+	code := app.Run(args)
+	return code, out.String(), errOut.String()
+}
+
+func TestSubcommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+
+	steps := []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{"add", "Pay", "rent", "--due", "2024-10-01", "--priority", "high", "--tag", "home"}, ExitOK, "Task 1 added!\n"},
+		{[]string{"add", "--tag", "work", "Write report"}, ExitOK, "Task 2 added!\n"},
+		{[]string{"add", "Book flights"}, ExitOK, "Task 3 added!\n"},
+		{[]string{"done", "2"}, ExitOK, "Task 2 marked as complete\n"},
+		{[]string{"rm", "3"}, ExitOK, "Task 3 deleted\n"},
+		{[]string{"add", "Plan offsite"}, ExitOK, "Task 4 added!\n"},
+		{[]string{"ls"}, ExitOK, "1 [ ] Pay rent (due 2024-10-01, high) #home\n2 [x] Write report #work\n4 [ ] Plan offsite\n"},
+		{[]string{"list", "--overdue"}, ExitOK, "1 [ ] Pay rent (due 2024-10-01, high) #home\n"},
+		{[]string{"ls", "--tag", "nothing"}, ExitOK, "No task found\n"},
+		{[]string{"edit", "4", "Plan", "team", "offsite"}, ExitOK, "Task updated\n"},
+		{[]string{"due", "4", "tomorrow"}, ExitOK, "Task updated\n"},
+		{[]string{"agenda"}, ExitOK, "Overdue:\n  1 [ ] Pay rent (due 2024-10-01, high) #home\nThis week:\n  4 [ ] Plan team offsite (due 2024-10-16)\n"},
+	}
+	for _, s := range steps {
+		code, out, errOut := run(t, file, s.args...)
+		if code != s.code || out != s.out {
+			t.Errorf("%v: expected code %d and output %q, got %d, %q (stderr %q)", s.args, s.code, s.out, code, out, errOut)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+	run(t, file, "add", "something")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"frobnicate"}, ExitUsage, `unknown command "frobnicate"`},
+		{[]string{"done"}, ExitUsage, "task ID required"},
+		{[]string{"done", "x"}, ExitUsage, `invalid task id "x"`},
+		{[]string{"done", "9"}, ExitError, "task 9 not found"},
+		{[]string{"add"}, ExitUsage, "usage: add <description>"},
+		{[]string{"add", "x", "--due", "someday"}, ExitUsage, `invalid date "someday"`},
+		{[]string{"ls", "--sort", "color"}, ExitUsage, "invalid sort key"},
+		{[]string{"ls", "--bogus"}, ExitUsage, "flag provided but not defined"},
+		{[]string{"undo"}, ExitUsage, "only available in the shell"},
+		{[]string{"import", "tasks.csv"}, ExitError, "unsupported file type"},
+	}
+	for _, tt := range tests {
+		code, _, errOut := run(t, file, tt.args...)
+		if code != tt.code || !strings.Contains(errOut, tt.stderr) {
+			t.Errorf("%v: expected code %d and stderr containing %q, got %d, %q", tt.args, tt.code, tt.stderr, code, errOut)
+		}
+	}
+
+	// Failed commands must not have changed the file.
+	_, out, _ := run(t, file, "ls")
+	if out != "1 [ ] something\n" {
+		t.Errorf("unexpected tasks after failures: %q", out)
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+	run(t, file, "add", "Pay rent", "--due", "2024-11-01", "--tag", "home", "--repeat", "monthly", "--note", "joint account")
+	run(t, file, "add", "Go to gym")
+	run(t, file, "complete", "2")
+
+	code, out, _ := run(t, file, "ls", "--json")
+	if code != ExitOK {
+		t.Fatalf("ls --json exited with %d", code)
+	}
+	var tasks []taskJSON
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	expected := []taskJSON{
+		{ID: 1, Description: "Pay rent", Due: "2024-11-01", Tags: []string{"home"}, Notes: "joint account", Repeat: "FREQ=MONTHLY"},
+		{ID: 2, Description: "Go to gym", Completed: true},
+	}
+	if !reflect.DeepEqual(expected, tasks) {
+		t.Errorf("expected %+v, got %+v", expected, tasks)
+	}
+
+	_, out, _ = run(t, file, "ls", "--json", "--tag", "none")
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON array, got %q", out)
+	}
+}
+
+func TestShell(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.txt")
+	input := strings.Join([]string{
+		`add "Buy milk" --tag shop`,
+		`add Call mom`,
+		`complete 1`,
+		`undo`,
+		`undo`,
+		`redo`,
+		`note 2 'ask about "dinner"'`,
+		`bogus`,
+		`add "Unclosed`,
+		`ls`,
+		`exit`,
+	}, "\n")
+
+	var out, errOut bytes.Buffer
+	app := &App{File: file, In: strings.NewReader(input), Out: &out, Err: &errOut}
+	if code := app.Run([]string{"shell"}); code != ExitOK {
+		t.Fatalf("shell exited with %d", code)
+	}
+
+	lines := strings.Split(out.String(), "Enter command")
+	var replies []string
+	for _, l := range lines[1:] {
+		_, reply, _ := strings.Cut(l, ": ")
+		replies = append(replies, reply)
+	}
+	expected := []string{
+		"Task 1 added!\n",
+		"Task 2 added!\n",
+		"Task 1 marked as complete\n",
+		"Undid complete\n",
+		"Undid add\n",
+		"Redid add\n",
+		"Task updated\n",
+		"",
+		"",
+		"1 [ ] Buy milk #shop\n2 [ ] Call mom\n    ask about \"dinner\"\n",
+		"Exiting the application\n",
+	}
+	if !reflect.DeepEqual(expected, replies) {
+		t.Errorf("expected replies\n%q\ngot\n%q", expected, replies)
+	}
+	// Errors go to Err, not between the prompts.
+	if !strings.HasPrefix(errOut.String(), "unknown command \"bogus\", run \"help\" for a list of commands\n") ||
+		strings.Count(errOut.String(), "\n") != 2 {
+		t.Errorf("unexpected errors %q", errOut.String())
+	}
+
+	// Every change was saved as it happened.
+	_, listed, _ := run(t, file, "ls")
+	if listed != "1 [ ] Buy milk #shop\n2 [ ] Call mom\n    ask about \"dinner\"\n" {
+		t.Errorf("unexpected saved tasks %q", listed)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		`add Buy milk`:               {"add", "Buy", "milk"},
+		`add "Buy  milk" --tag shop`: {"add", "Buy  milk", "--tag", "shop"},
+		`note 1 'it\'s'`:             nil,
+		`note 1 "say \"hi\""`:        {"note", "1", `say "hi"`},
+		`note 1 it\'s`:               {"note", "1", "it's"},
+		`  `:                         nil,
+		`add ""`:                     {"add", ""},
+	}
+	for input, expected := range tests {
+		got, err := SplitArgs(input)
+		if expected == nil && input != "  " {
+			if err == nil {
+				t.Errorf("SplitArgs(%q): expected error, got %q", input, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, expected) {
+			t.Errorf("SplitArgs(%q) = %q, %v, expected %q", input, got, err, expected)
+		}
+	}
+}
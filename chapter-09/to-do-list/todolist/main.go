@@ -1,64 +1,35 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
-	"todolist/todo"
+	"todolist/cli"
 )
 
 func main() {
-	tasks, err := todo.LoadTasksFromFile("tasks.txt")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading tasks from file: %v\n", err)
-		os.Exit(1)
+	file := flag.String("f", "tasks.txt", "task file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: todolist [-f file] [command [args]]\n")
+		fmt.Fprintf(os.Stderr, "Without a command, or with \"shell\", starts the interactive shell.\n")
+		fmt.Fprintf(os.Stderr, "Run \"todolist help\" for the list of commands.\n")
 	}
+	flag.Parse()
 
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Print("Enter command (add/list/complete/exit): ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+	if env := os.Getenv("TODOLIST_FILE"); env != "" && !isFlagSet("f") {
+		*file = env
+	}
 
-		parts := strings.SplitN(input, " ", 2)
-		command := parts[0]
+	app := &cli.App{File: *file, In: os.Stdin, Out: os.Stdout, Err: os.Stderr}
+	os.Exit(app.Run(flag.Args()))
+}
 
-		switch command {
-		case "add":
-			if len(parts) < 2 {
-				fmt.Println("Description required!")
-				continue
-			}
-			todo.AddTask(&tasks, parts[1])
-			err = todo.SaveTasksToFile(tasks, "tasks.txt")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error saving tasks to file: %v\n", err)
-			}
-		case "list":
-			todo.ListTasks(tasks)
-		case "complete":
-			if len(parts) < 2 {
-				fmt.Println("Task ID required!")
-				continue
-			}
-			id, err := strconv.Atoi(parts[1])
-			if err != nil {
-				fmt.Println("Invalid task id!")
-				continue
-			}
-			todo.CompleteTask(&tasks, id)
-			err = todo.SaveTasksToFile(tasks, "tasks.txt")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error saving tasks to file: %v\n", err)
-			}
-		case "exit":
-			fmt.Println("Exiting the application")
-			return
-		default:
-			fmt.Println("Invalid command. Please enter add/list/complete or exit")
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
-	}
+	})
+	return set
 }
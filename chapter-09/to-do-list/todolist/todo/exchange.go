@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportFile writes tasks to filename as iCalendar (.ics) or Markdown
+// (.md, .markdown), chosen by the file extension.
+func ExportFile(tasks []Task, filename string) (err error) {
+	format, err := exchangeFormat(filename)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if format == "ics" {
+		return ExportICal(file, tasks)
+	}
+	return ExportMarkdown(file, tasks)
+}
+
+// ImportFile reads tasks from an iCalendar or Markdown file, chosen by the
+// file extension.
+func ImportFile(filename string) ([]Task, error) {
+	format, err := exchangeFormat(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if format == "ics" {
+		return ImportICal(file)
+	}
+	return ImportMarkdown(file)
+}
+
+func exchangeFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ics", ".ical":
+		return "ics", nil
+	case ".md", ".markdown":
+		return "md", nil
+	}
+	return "", fmt.Errorf("unsupported file type %q, expected .ics or .md", filepath.Ext(filename))
+}
+
+// Import appends tasks to the list under new IDs and returns them as added.
+// The whole import is undone as a single operation.
+func (l *List) Import(tasks []Task) []Task {
+	var added []Task
+	var changes []change
+	for _, task := range tasks {
+		t := task.clone()
+		t.ID = l.NextID
+		l.NextID++
+		l.Tasks = append(l.Tasks, *t)
+		changes = append(changes, change{index: len(l.Tasks) - 1, after: t.clone()})
+		added = append(added, *t.clone())
+	}
+	if len(changes) > 0 {
+		l.record("import", changes...)
+	}
+	return added
+}
@@ -0,0 +1,170 @@
+package todo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Filter selects a subset of tasks. Zero-valued fields match everything.
+type Filter struct {
+	Tag         string
+	Priority    Priority
+	Overdue     bool
+	HideDone    bool
+	OnlyDone    bool
+	DueBy       time.Time
+	SortBy      string
+	Description string
+}
+
+// FilterTasks returns the tasks matching f, ordered by f.SortBy.
+// now is used to decide which tasks are overdue.
+func FilterTasks(tasks []Task, f Filter, now time.Time) ([]Task, error) {
+	var matched []Task
+	for _, task := range tasks {
+		if f.Tag != "" && !task.HasTag(normalizeTag(f.Tag)) {
+			continue
+		}
+		if f.Priority != PriorityNone && task.Priority != f.Priority {
+			continue
+		}
+		if f.Overdue && !task.IsOverdue(now) {
+			continue
+		}
+		if f.HideDone && task.Completed {
+			continue
+		}
+		if f.OnlyDone && !task.Completed {
+			continue
+		}
+		if !f.DueBy.IsZero() && (!task.HasDue() || task.Due.After(f.DueBy)) {
+			continue
+		}
+		if f.Description != "" && !strings.Contains(strings.ToLower(task.Description), strings.ToLower(f.Description)) {
+			continue
+		}
+		matched = append(matched, task)
+	}
+	if err := SortTasks(matched, f.SortBy); err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// SortTasks orders tasks in place by "id" (the default), "due", "priority"
+// or "description". Tasks without a due date sort after dated ones, and
+// higher priorities come first. Ties keep ID order.
+func SortTasks(tasks []Task, by string) error {
+	var less func(a, b Task) bool
+	switch strings.ToLower(by) {
+	case "", "id":
+		less = func(a, b Task) bool { return false }
+	case "due":
+		less = func(a, b Task) bool {
+			if a.HasDue() != b.HasDue() {
+				return a.HasDue()
+			}
+			return a.Due.Before(b.Due)
+		}
+	case "priority":
+		less = func(a, b Task) bool { return a.Priority > b.Priority }
+	case "description":
+		less = func(a, b Task) bool {
+			return strings.ToLower(a.Description) < strings.ToLower(b.Description)
+		}
+	default:
+		return fmt.Errorf("invalid sort key %q, expected id, due, priority or description", by)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if less(tasks[i], tasks[j]) {
+			return true
+		}
+		if less(tasks[j], tasks[i]) {
+			return false
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return nil
+}
+
+// AgendaGroup is one section of the agenda view.
+type AgendaGroup struct {
+	Title string
+	Tasks []Task
+}
+
+// Agenda groups open tasks into Overdue, Today, This week (the next six
+// days), Later and No due date, each sorted by due date then priority.
+// Empty groups are left out.
+func Agenda(tasks []Task, now time.Time) []AgendaGroup {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	weekEnd := today.AddDate(0, 0, 7)
+
+	groups := []AgendaGroup{
+		{Title: "Overdue"},
+		{Title: "Today"},
+		{Title: "This week"},
+		{Title: "Later"},
+		{Title: "No due date"},
+	}
+	for _, task := range tasks {
+		if task.Completed {
+			continue
+		}
+		var idx int
+		switch {
+		case !task.HasDue():
+			idx = 4
+		case task.Due.Before(today):
+			idx = 0
+		case task.Due.Before(tomorrow):
+			idx = 1
+		case task.Due.Before(weekEnd):
+			idx = 2
+		default:
+			idx = 3
+		}
+		groups[idx].Tasks = append(groups[idx].Tasks, task)
+	}
+
+	var result []AgendaGroup
+	for _, g := range groups {
+		if len(g.Tasks) == 0 {
+			continue
+		}
+		sort.SliceStable(g.Tasks, func(i, j int) bool {
+			a, b := g.Tasks[i], g.Tasks[j]
+			if !a.Due.Equal(b.Due) {
+				return a.Due.Before(b.Due)
+			}
+			return a.Priority > b.Priority
+		})
+		result = append(result, g)
+	}
+	return result
+}
+
+// PrintAgenda prints the agenda view produced by Agenda.
+func PrintAgenda(tasks []Task, now time.Time) {
+	WriteAgenda(os.Stdout, tasks, now)
+}
+
+// WriteAgenda writes the agenda view produced by Agenda to w.
+func WriteAgenda(w io.Writer, tasks []Task, now time.Time) {
+	groups := Agenda(tasks, now)
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "Nothing on the agenda")
+		return
+	}
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s:\n", g.Title)
+		for _, task := range g.Tasks {
+			fmt.Fprintf(w, "  %s\n", FormatTask(task))
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func ids(tasks []Task) []int {
+	var result []int
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+	return result
+}
+
+func sampleTasks() []Task {
+	return []Task{
+		{ID: 1, Description: "Pay rent", Due: day(2024, 10, 1), Priority: PriorityHigh, Tags: []string{"home"}},
+		{ID: 2, Description: "Write report", Due: day(2024, 10, 15), Priority: PriorityMedium, Tags: []string{"work"}},
+		{ID: 3, Description: "Book flights", Due: day(2024, 10, 18), Tags: []string{"travel"}},
+		{ID: 4, Description: "Plan offsite", Due: day(2024, 12, 1), Priority: PriorityLow, Tags: []string{"work"}},
+		{ID: 5, Description: "Read a book"},
+		{ID: 6, Description: "File expenses", Due: day(2024, 10, 2), Completed: true, Tags: []string{"work"}},
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	now := time.Date(2024, 10, 15, 14, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []int
+	}{
+		{"all", Filter{}, []int{1, 2, 3, 4, 5, 6}},
+		{"tag", Filter{Tag: "WORK"}, []int{2, 4, 6}},
+		{"tag open sorted by due", Filter{Tag: "work", HideDone: true, SortBy: "due"}, []int{2, 4}},
+		{"overdue", Filter{Overdue: true}, []int{1}},
+		{"done", Filter{OnlyDone: true}, []int{6}},
+		{"priority", Filter{Priority: PriorityHigh}, []int{1}},
+		{"due by", Filter{DueBy: day(2024, 10, 15)}, []int{1, 2, 6}},
+		{"description", Filter{Description: "BOOK"}, []int{3, 5}},
+		{"sort priority", Filter{SortBy: "priority"}, []int{1, 2, 4, 3, 5, 6}},
+		{"sort due", Filter{SortBy: "due"}, []int{1, 6, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		got, err := FilterTasks(sampleTasks(), tt.filter, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(ids(got), tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, ids(got))
+		}
+	}
+
+	if _, err := FilterTasks(sampleTasks(), Filter{SortBy: "color"}, now); err == nil {
+		t.Error("expected error for invalid sort key")
+	}
+}
+
+func TestAgenda(t *testing.T) {
+	now := time.Date(2024, 10, 15, 14, 0, 0, 0, time.Local)
+	groups := Agenda(sampleTasks(), now)
+
+	expected := map[string][]int{
+		"Overdue":     {1},
+		"Today":       {2},
+		"This week":   {3},
+		"Later":       {4},
+		"No due date": {5},
+	}
+	var titles []string
+	for _, g := range groups {
+		titles = append(titles, g.Title)
+		if !reflect.DeepEqual(ids(g.Tasks), expected[g.Title]) {
+			t.Errorf("%s: expected %v, got %v", g.Title, expected[g.Title], ids(g.Tasks))
+		}
+	}
+	expectedTitles := []string{"Overdue", "Today", "This week", "Later", "No due date"}
+	if !reflect.DeepEqual(titles, expectedTitles) {
+		t.Errorf("expected groups %v, got %v", expectedTitles, titles)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh} {
+		got, err := ParsePriority(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParsePriority(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+}
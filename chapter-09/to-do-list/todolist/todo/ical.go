@@ -0,0 +1,299 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icalLineLimit is the maximum line length in octets before folding (RFC 5545 3.1).
+const icalLineLimit = 75
+
+// ExportICal writes tasks as a VCALENDAR containing one VTODO per task.
+func ExportICal(w io.Writer, tasks []Task) error {
+	bw := bufio.NewWriter(w)
+	stamp := now().UTC().Format("20060102T150405Z")
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//gotutorials//todolist//EN",
+	}
+	for _, task := range tasks {
+		lines = append(lines,
+			"BEGIN:VTODO",
+			fmt.Sprintf("UID:task-%d@todolist", task.ID),
+			"DTSTAMP:"+stamp,
+			"SUMMARY:"+escapeICalText(task.Description),
+		)
+		if task.Completed {
+			lines = append(lines, "STATUS:COMPLETED")
+		} else {
+			lines = append(lines, "STATUS:NEEDS-ACTION")
+		}
+		if task.HasDue() {
+			lines = append(lines, "DUE;VALUE=DATE:"+task.Due.Format("20060102"))
+		}
+		if p := icalPriority(task.Priority); p > 0 {
+			lines = append(lines, "PRIORITY:"+strconv.Itoa(p))
+		}
+		if len(task.Tags) > 0 {
+			var tags []string
+			for _, tag := range task.Tags {
+				tags = append(tags, escapeICalText(tag))
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		if task.Notes != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICalText(task.Notes))
+		}
+		if !task.Recurrence.IsZero() {
+			lines = append(lines, "RRULE:"+task.Recurrence.String())
+		}
+		lines = append(lines, "END:VTODO")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(foldICalLine(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ImportICal reads the VTODO components of a calendar. Other components such
+// as VEVENT are ignored. Imported tasks are numbered from 1 in file order.
+func ImportICal(r io.Reader) ([]Task, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	var current *Task
+	for n, line := range lines {
+		name, params, value, ok := splitICalLine(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid content line %q", n+1, line)
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO"):
+			current = &Task{ID: len(tasks) + 1}
+			continue
+		case name == "END" && strings.EqualFold(value, "VTODO"):
+			if current != nil {
+				tasks = append(tasks, *current)
+				current = nil
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch name {
+		case "SUMMARY":
+			current.Description = unescapeICalText(value)
+		case "DESCRIPTION":
+			current.Notes = unescapeICalText(value)
+		case "STATUS":
+			current.Completed = strings.EqualFold(value, "COMPLETED")
+		case "COMPLETED":
+			current.Completed = true
+		case "PERCENT-COMPLETE":
+			if value == "100" {
+				current.Completed = true
+			}
+		case "DUE":
+			due, err := parseICalDate(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			current.Due = due
+		case "PRIORITY":
+			p, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid PRIORITY %q", n+1, value)
+			}
+			current.Priority = priorityFromICal(p)
+		case "CATEGORIES":
+			for _, tag := range splitICalList(value) {
+				if tag = normalizeTag(unescapeICalText(tag)); tag != "" && !current.HasTag(tag) {
+					current.Tags = append(current.Tags, tag)
+				}
+			}
+		case "RRULE":
+			rule, err := ParseRecurrence(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			current.Recurrence = rule
+		}
+	}
+	return tasks, nil
+}
+
+// icalPriority maps priorities onto the RFC 5545 scale, where 1 is the
+// highest, 9 the lowest and 0 undefined.
+func icalPriority(p Priority) int {
+	switch p {
+	case PriorityHigh:
+		return 1
+	case PriorityMedium:
+		return 5
+	case PriorityLow:
+		return 9
+	}
+	return 0
+}
+
+func priorityFromICal(p int) Priority {
+	switch {
+	case p >= 1 && p <= 4:
+		return PriorityHigh
+	case p == 5:
+		return PriorityMedium
+	case p >= 6 && p <= 9:
+		return PriorityLow
+	}
+	return PriorityNone
+}
+
+// parseICalDate accepts DATE values (20241101) and DATE-TIME values
+// (20241101T090000 or 20241101T090000Z). Only the date part is kept, in local
+// time for UTC values.
+func parseICalDate(value string, params map[string]string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		return startOfDay(t.Local()), nil
+	}
+	loc := time.Local
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if len(value) > 8 {
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		return startOfDay(t.Local()), nil
+	}
+	t, err := time.ParseInLocation("20060102", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return t, nil
+}
+
+// unfoldICalLines joins folded continuation lines, which start with a space
+// or tab, onto the previous line.
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICalLine splits "NAME;PARAM=VALUE:value" into its parts. Names and
+// parameter names are upper-cased.
+func splitICalLine(line string) (string, map[string]string, string, bool) {
+	// The value starts after the first colon that is not inside a quoted
+	// parameter value.
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value, true
+}
+
+// splitICalList splits a comma-separated value, honouring escaped commas.
+func splitICalList(value string) []string {
+	var items []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(items, current.String())
+}
+
+var (
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escapeICalText(s string) string   { return icalEscaper.Replace(s) }
+func unescapeICalText(s string) string { return icalUnescaper.Replace(s) }
+
+// foldICalLine terminates a content line with CRLF, splitting it into
+// continuation lines so that none exceeds icalLineLimit octets. Splits never
+// fall inside a multi-byte UTF-8 sequence.
+func foldICalLine(line string) string {
+	var b strings.Builder
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,103 @@
+package todo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICalRoundTrip(t *testing.T) {
+	tasks := []Task{
+		{
+			ID:          1,
+			Description: "Pay rent; landlord, flat 3",
+			Due:         day(2024, 11, 1),
+			Priority:    PriorityHigh,
+			Tags:        []string{"home", "money"},
+			Notes:       "Transfer before noon\nuse the joint account",
+			Recurrence:  Recurrence{Freq: Monthly, ByMonthDay: []int{1}},
+		},
+		{ID: 2, Description: "Go to gym", Completed: true, Priority: PriorityLow},
+		{ID: 3, Description: strings.Repeat("ünïcödé ", 20)},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportICal(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("line longer than %d octets: %q", icalLineLimit, line)
+		}
+	}
+
+	imported, err := ImportICal(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tasks, imported) {
+		t.Errorf("expected %+v, got %+v", tasks, imported)
+	}
+}
+
+func TestImportICal(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Not a task\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:abc\r\n" +
+		"SUMMARY:Submit expense\r\n" +
+		" s report\r\n" +
+		"DUE;TZID=Europe/Berlin:20241105T170000\r\n" +
+		"PRIORITY:3\r\n" +
+		"CATEGORIES:Work,Finance\\,Q4\r\n" +
+		"PERCENT-COMPLETE:100\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:Call mom\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"DUE;VALUE=DATE:20240229\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	tasks, err := ImportICal(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	expected := []Task{
+		{
+			ID:          1,
+			Description: "Submit expenses report",
+			Completed:   true,
+			Due:         startOfDay(time.Date(2024, 11, 5, 17, 0, 0, 0, berlin).Local()),
+			Priority:    PriorityHigh,
+			Tags:        []string{"Work", "FinanceQ4"},
+		},
+		{ID: 2, Description: "Call mom", Due: day(2024, 2, 29)},
+	}
+	if !reflect.DeepEqual(expected, tasks) {
+		t.Errorf("expected %+v, got %+v", expected, tasks)
+	}
+}
+
+func TestImportICalErrors(t *testing.T) {
+	inputs := []string{
+		"BEGIN:VTODO\nDUE:2024-11-01\nEND:VTODO\n",
+		"BEGIN:VTODO\nPRIORITY:high\nEND:VTODO\n",
+		"BEGIN:VTODO\nRRULE:FREQ=SECONDLY\nEND:VTODO\n",
+		"BEGIN:VTODO\nno colon here\nEND:VTODO\n",
+	}
+	for _, input := range inputs {
+		if _, err := ImportICal(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
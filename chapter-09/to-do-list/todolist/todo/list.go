@@ -0,0 +1,348 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// List is a task list with stable IDs and an undo/redo history.
+// IDs come from NextID and are never reused, even after a task is deleted
+// or an add is undone.
+type List struct {
+	Tasks  []Task
+	NextID int
+
+	undo []operation
+	redo []operation
+}
+
+// operation is one entry in the operation log: the set of task changes made
+// by a single command, so that it can be reverted or replayed as a unit.
+type operation struct {
+	name    string
+	changes []change
+}
+
+// change records a task before and after a mutation. before is nil for an
+// insertion and after is nil for a deletion. index is the position the task
+// held in the list, used to put deleted tasks back where they were.
+type change struct {
+	index  int
+	before *Task
+	after  *Task
+}
+
+// NewList returns a list holding tasks, with NextID set past the highest ID.
+func NewList(tasks []Task) *List {
+	l := &List{Tasks: tasks}
+	l.NextID = nextID(tasks, 1)
+	return l
+}
+
+// LoadList reads a list and its ID counter from filename.
+func LoadList(filename string) (*List, error) {
+	tasks, next, err := readStore(filename)
+	if err != nil {
+		return nil, err
+	}
+	l := NewList(tasks)
+	if next > l.NextID {
+		l.NextID = next
+	}
+	return l, nil
+}
+
+// Save writes the list and its ID counter to filename.
+func (l *List) Save(filename string) error {
+	return writeStore(l.Tasks, l.NextID, filename)
+}
+
+// Find returns a copy of the task with the given ID.
+func (l *List) Find(id int) (Task, error) {
+	i, err := l.indexOf(id)
+	if err != nil {
+		return Task{}, err
+	}
+	return l.Tasks[i], nil
+}
+
+// Add appends a new task and returns it.
+func (l *List) Add(description string) Task {
+	return l.AddTask(Task{Description: description})
+}
+
+// AddTask appends a copy of task under the next ID and returns it. It lets
+// callers set due date, priority and the like in a single undoable step.
+func (l *List) AddTask(task Task) Task {
+	t := task.clone()
+	t.Tags = nil
+	for _, tag := range task.Tags {
+		if tag = normalizeTag(tag); tag != "" && !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	if t.HasDue() {
+		t.Due = startOfDay(t.Due)
+	}
+	t.ID = l.NextID
+	l.NextID++
+	l.Tasks = append(l.Tasks, *t)
+	l.record("add", change{index: len(l.Tasks) - 1, after: t.clone()})
+	return *t.clone()
+}
+
+// Delete removes the task with the given ID.
+func (l *List) Delete(id int) error {
+	i, err := l.indexOf(id)
+	if err != nil {
+		return err
+	}
+	removed := l.Tasks[i]
+	l.Tasks = append(l.Tasks[:i], l.Tasks[i+1:]...)
+	l.record("delete", change{index: i, before: removed.clone()})
+	return nil
+}
+
+// now is the clock used to schedule recurring tasks that have no due date.
+var now = time.Now
+
+// Complete marks a task as done. If the task recurs, the next occurrence is
+// added as a new task and returned; completing and spawning are undone
+// together. The next due date follows the rule from the current due date,
+// or from today when the task has none. Completing a task that is already
+// done is an error.
+func (l *List) Complete(id int) (*Task, error) {
+	i, err := l.indexOf(id)
+	if err != nil {
+		return nil, err
+	}
+	before := l.Tasks[i].clone()
+	if before.Completed {
+		return nil, fmt.Errorf("task %d already completed", id)
+	}
+	done := before.clone()
+	done.Completed = true
+	l.Tasks[i] = *done
+	changes := []change{{index: i, before: before, after: done.clone()}}
+
+	var spawned *Task
+	from := before.Due
+	if from.IsZero() {
+		from = now()
+	}
+	if due, ok := before.Recurrence.Next(from); ok {
+		spawned = before.clone()
+		spawned.ID = l.NextID
+		spawned.Due = due
+		spawned.Recurrence = before.Recurrence.advance()
+		l.NextID++
+		l.Tasks = append(l.Tasks, *spawned.clone())
+		changes = append(changes, change{index: len(l.Tasks) - 1, after: spawned.clone()})
+	}
+	l.record("complete", changes...)
+	return spawned, nil
+}
+
+// Uncomplete marks a task as not done.
+func (l *List) Uncomplete(id int) error {
+	return l.update("uncomplete", id, func(t *Task) error {
+		t.Completed = false
+		return nil
+	})
+}
+
+// Edit replaces the description of a task.
+func (l *List) Edit(id int, description string) error {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return errors.New("description required")
+	}
+	return l.update("edit", id, func(t *Task) error {
+		t.Description = description
+		return nil
+	})
+}
+
+// SetDue sets the due date of a task; a zero time clears it.
+func (l *List) SetDue(id int, due time.Time) error {
+	return l.update("due", id, func(t *Task) error {
+		if !due.IsZero() {
+			due = startOfDay(due)
+		}
+		t.Due = due
+		return nil
+	})
+}
+
+// SetPriority sets the priority of a task.
+func (l *List) SetPriority(id int, p Priority) error {
+	return l.update("priority", id, func(t *Task) error {
+		t.Priority = p
+		return nil
+	})
+}
+
+// AddTags attaches tags to a task, skipping ones it already has.
+func (l *List) AddTags(id int, tags ...string) error {
+	return l.update("tag", id, func(t *Task) error {
+		for _, tag := range tags {
+			tag = normalizeTag(tag)
+			if tag == "" || t.HasTag(tag) {
+				continue
+			}
+			t.Tags = append(t.Tags, tag)
+		}
+		return nil
+	})
+}
+
+// RemoveTags detaches tags from a task.
+func (l *List) RemoveTags(id int, tags ...string) error {
+	return l.update("untag", id, func(t *Task) error {
+		var kept []string
+		for _, existing := range t.Tags {
+			drop := false
+			for _, tag := range tags {
+				if strings.EqualFold(existing, normalizeTag(tag)) {
+					drop = true
+					break
+				}
+			}
+			if !drop {
+				kept = append(kept, existing)
+			}
+		}
+		t.Tags = kept
+		return nil
+	})
+}
+
+// SetNotes replaces the free-form notes of a task.
+func (l *List) SetNotes(id int, notes string) error {
+	return l.update("note", id, func(t *Task) error {
+		t.Notes = strings.TrimSpace(notes)
+		return nil
+	})
+}
+
+// SetRecurrence sets how a task repeats; the zero rule stops it repeating.
+func (l *List) SetRecurrence(id int, r Recurrence) error {
+	return l.update("repeat", id, func(t *Task) error {
+		t.Recurrence = r
+		return nil
+	})
+}
+
+// Undo reverts the most recent operation and returns its name.
+func (l *List) Undo() (string, error) {
+	if len(l.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	op := l.undo[len(l.undo)-1]
+	l.undo = l.undo[:len(l.undo)-1]
+	for i := len(op.changes) - 1; i >= 0; i-- {
+		c := op.changes[i]
+		l.apply(c.index, c.after, c.before)
+	}
+	l.redo = append(l.redo, op)
+	return op.name, nil
+}
+
+// Redo replays the most recently undone operation and returns its name.
+func (l *List) Redo() (string, error) {
+	if len(l.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	op := l.redo[len(l.redo)-1]
+	l.redo = l.redo[:len(l.redo)-1]
+	for _, c := range op.changes {
+		l.apply(c.index, c.before, c.after)
+	}
+	l.undo = append(l.undo, op)
+	return op.name, nil
+}
+
+// update runs fn on a copy of the task and commits it only if fn succeeds.
+func (l *List) update(name string, id int, fn func(*Task) error) error {
+	i, err := l.indexOf(id)
+	if err != nil {
+		return err
+	}
+	before := l.Tasks[i].clone()
+	updated := l.Tasks[i].clone()
+	if err := fn(updated); err != nil {
+		return err
+	}
+	l.Tasks[i] = *updated
+	l.record(name, change{index: i, before: before, after: updated.clone()})
+	return nil
+}
+
+// record appends an operation to the undo log. A new operation invalidates
+// whatever could have been redone.
+func (l *List) record(name string, changes ...change) {
+	l.undo = append(l.undo, operation{name: name, changes: changes})
+	l.redo = nil
+}
+
+// apply moves a task from state from to state to.
+func (l *List) apply(index int, from, to *Task) {
+	switch {
+	case from == nil && to != nil:
+		if index > len(l.Tasks) {
+			index = len(l.Tasks)
+		}
+		l.Tasks = append(l.Tasks, Task{})
+		copy(l.Tasks[index+1:], l.Tasks[index:])
+		l.Tasks[index] = *to.clone()
+	case from != nil && to == nil:
+		if i, err := l.indexOf(from.ID); err == nil {
+			l.Tasks = append(l.Tasks[:i], l.Tasks[i+1:]...)
+		}
+	case from != nil && to != nil:
+		if i, err := l.indexOf(from.ID); err == nil {
+			l.Tasks[i] = *to.clone()
+		}
+	}
+}
+
+func (l *List) indexOf(id int) (int, error) {
+	for i := range l.Tasks {
+		if l.Tasks[i].ID == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("task %d not found", id)
+}
+
+// clone returns a copy of the task that shares no slices with t.
+func (t Task) clone() *Task {
+	if t.Tags != nil {
+		t.Tags = append([]string(nil), t.Tags...)
+	}
+	if t.Recurrence.ByDay != nil {
+		t.Recurrence.ByDay = append([]time.Weekday(nil), t.Recurrence.ByDay...)
+	}
+	if t.Recurrence.ByMonthDay != nil {
+		t.Recurrence.ByMonthDay = append([]int(nil), t.Recurrence.ByMonthDay...)
+	}
+	return &t
+}
+
+// nextID returns the smallest ID above every task in tasks, but at least min.
+func nextID(tasks []Task, min int) int {
+	next := min
+	for _, task := range tasks {
+		if task.ID >= next {
+			next = task.ID + 1
+		}
+	}
+	return next
+}
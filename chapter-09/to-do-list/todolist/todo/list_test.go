@@ -0,0 +1,200 @@
+package todo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListIDsAreNeverReused(t *testing.T) {
+	l := NewList(nil)
+	a := l.Add("a")
+	b := l.Add("b")
+	if err := l.Delete(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	c := l.Add("c")
+	if a.ID != 1 || b.ID != 2 || c.ID != 3 {
+		t.Errorf("unexpected IDs %d, %d, %d", a.ID, b.ID, c.ID)
+	}
+
+	if _, err := l.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if d := l.Add("d"); d.ID != 4 {
+		t.Errorf("expected ID 4 after undoing an add, got %d", d.ID)
+	}
+}
+
+func TestListErrorsOnUnknownID(t *testing.T) {
+	l := NewList([]Task{{ID: 1, Description: "a"}})
+	_, completeErr := l.Complete(9)
+	checks := map[string]error{
+		"complete":   completeErr,
+		"uncomplete": l.Uncomplete(9),
+		"delete":     l.Delete(9),
+		"edit":       l.Edit(9, "x"),
+		"priority":   l.SetPriority(9, PriorityHigh),
+	}
+	for name, err := range checks {
+		if err == nil {
+			t.Errorf("%s: expected error for unknown task", name)
+		}
+	}
+	if err := l.Edit(1, "  "); err == nil {
+		t.Error("expected error for empty description")
+	}
+	if _, err := l.Undo(); err != ErrNothingToUndo {
+		t.Errorf("failed operations should not be recorded, got %v", err)
+	}
+}
+
+func TestListSetters(t *testing.T) {
+	l := NewList([]Task{{ID: 1, Description: "Write report"}})
+	now := time.Date(2024, 10, 15, 9, 30, 0, 0, time.Local)
+
+	due, err := ParseDate("tomorrow", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []error{
+		l.SetDue(1, due),
+		l.SetPriority(1, PriorityMedium),
+		l.AddTags(1, "#work", "q4", "Work"),
+		l.RemoveTags(1, "q4"),
+		l.SetNotes(1, "  draft in docs  "),
+		l.Edit(1, "Write quarterly report"),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	expected := Task{
+		ID:          1,
+		Description: "Write quarterly report",
+		Due:         time.Date(2024, 10, 16, 0, 0, 0, 0, time.Local),
+		Priority:    PriorityMedium,
+		Tags:        []string{"work"},
+		Notes:       "draft in docs",
+	}
+	if !reflect.DeepEqual(expected, l.Tasks[0]) {
+		t.Errorf("expected %v, got %v", expected, l.Tasks[0])
+	}
+}
+
+func TestListUndoRedo(t *testing.T) {
+	l := NewList([]Task{
+		{ID: 1, Description: "a"},
+		{ID: 2, Description: "b", Tags: []string{"x"}},
+		{ID: 3, Description: "c"},
+	})
+	original := cloneTasks(l.Tasks)
+
+	if _, err := l.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Complete(1); err == nil || err.Error() != "task 1 already completed" {
+		t.Errorf("Complete failed, expected an error for a completed task, got %v", err)
+	}
+	afterComplete := cloneTasks(l.Tasks)
+	mustDo(t, l.AddTags(2, "y"))
+	mustDo(t, l.Delete(2))
+	l.Add("d")
+	afterAll := cloneTasks(l.Tasks)
+
+	for i := 0; i < 3; i++ {
+		if _, err := l.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(l.Tasks, afterComplete) {
+		t.Errorf("after three undos expected %v, got %v", afterComplete, l.Tasks)
+	}
+	name, err := l.Undo()
+	if err != nil || name != "complete" {
+		t.Fatalf("expected to undo complete, got %q, %v", name, err)
+	}
+	if !reflect.DeepEqual(l.Tasks, original) {
+		t.Errorf("after undoing everything expected %v, got %v", original, l.Tasks)
+	}
+	if _, err := l.Undo(); err != ErrNothingToUndo {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := l.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(l.Tasks, afterAll) {
+		t.Errorf("after redoing everything expected %v, got %v", afterAll, l.Tasks)
+	}
+	if _, err := l.Redo(); err != ErrNothingToRedo {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+
+	// A new change after an undo discards the redo history.
+	if _, err := l.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	mustDo(t, l.Uncomplete(1))
+	if _, err := l.Redo(); err != ErrNothingToRedo {
+		t.Errorf("expected redo history to be cleared, got %v", err)
+	}
+}
+
+func TestListDeleteUndoKeepsPosition(t *testing.T) {
+	l := NewList([]Task{{ID: 1}, {ID: 2}, {ID: 3}})
+	mustDo(t, l.Delete(2))
+	if _, err := l.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(l.Tasks); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", got)
+	}
+}
+
+func TestLoadAndSaveList(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.txt")
+	l := NewList(nil)
+	l.Add("a")
+	l.Add("b")
+	mustDo(t, l.Delete(2))
+	if err := l.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NextID != 3 {
+		t.Errorf("expected NextID 3, got %d", loaded.NextID)
+	}
+	if !reflect.DeepEqual(loaded.Tasks, l.Tasks) {
+		t.Errorf("expected %v, got %v", l.Tasks, loaded.Tasks)
+	}
+
+	tasks, err := LoadTasksFromFile(filename)
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("LoadTasksFromFile should skip the header, got %v, %v", tasks, err)
+	}
+}
+
+func mustDo(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cloneTasks(tasks []Task) []Task {
+	var result []Task
+	for _, task := range tasks {
+		result = append(result, *task.clone())
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// checklistItem matches "- [ ] text", "* [x] text" and "+ [X] text", optionally indented.
+var checklistItem = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// ExportMarkdown writes tasks as a Markdown checklist. Due date, priority and
+// recurrence follow the description in parentheses, tags are appended as
+// #tags and notes are written as indented lines below the item, e.g.
+// "- [ ] Pay rent (due 2024-11-01, high) #home". A word of the description
+// that starts with # is written as \#, and a parenthesised group ending it
+// as \(...), so they are not read back as a tag or as details.
+func ExportMarkdown(w io.Writer, tasks []Task) error {
+	bw := bufio.NewWriter(w)
+	for _, task := range tasks {
+		status := "[ ]"
+		if task.Completed {
+			status = "[x]"
+		}
+		fmt.Fprintf(bw, "- %s %s%s\n", status, escapeMarkup(task.Description), taskSuffix(task))
+		if task.Notes != "" {
+			for _, line := range strings.Split(task.Notes, "\n") {
+				fmt.Fprintf(bw, "  %s\n", line)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ImportMarkdown reads the checklist items of a Markdown document in the
+// format written by ExportMarkdown. Lines that are not checklist items are
+// ignored unless they are indented under an item, in which case they become
+// its notes. Imported tasks are numbered from 1 in document order.
+func ImportMarkdown(r io.Reader) ([]Task, error) {
+	var tasks []Task
+	var notes []string
+	flush := func() {
+		if len(tasks) > 0 && len(notes) > 0 {
+			tasks[len(tasks)-1].Notes = strings.Join(notes, "\n")
+		}
+		notes = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	inItem := false
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if m := checklistItem.FindStringSubmatch(line); m != nil {
+			flush()
+			task := parseMarkdownItem(m[2])
+			task.ID = len(tasks) + 1
+			task.Completed = m[1] != " "
+			tasks = append(tasks, task)
+			inItem = true
+			continue
+		}
+		if inItem && (strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")) && strings.TrimSpace(line) != "" {
+			notes = append(notes, strings.TrimSpace(line))
+			continue
+		}
+		flush()
+		inItem = false
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// parseMarkdownItem splits the text of a checklist item into description,
+// trailing #tags and the optional "(due ..., priority, repeats ...)" group.
+// A parenthesised group that does not parse is kept in the description.
+func parseMarkdownItem(text string) Task {
+	var task Task
+	words := strings.Fields(text)
+	for len(words) > 0 && strings.HasPrefix(words[len(words)-1], "#") && normalizeTag(words[len(words)-1]) != "" {
+		words = words[:len(words)-1]
+	}
+	for _, tag := range strings.Fields(text)[len(words):] {
+		if tag = normalizeTag(tag); tag != "" && !task.HasTag(tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	desc := strings.Join(words, " ")
+
+	if strings.HasSuffix(desc, ")") {
+		if open := strings.LastIndex(desc, " ("); open >= 0 {
+			if parseTaskDetails(desc[open+2:len(desc)-1], &task) {
+				desc = desc[:open]
+			}
+		}
+	}
+	task.Description = unescapeMarkup(desc)
+	return task
+}
+
+// hashWord matches a # at the start of a word and detailsGroup a
+// parenthesised group at the end of the text, both after any backslashes.
+// The escaped forms have one more backslash in front of the # or (.
+var (
+	hashWord            = regexp.MustCompile(`(^|\s)(\\*)#`)
+	escapedHashWord     = regexp.MustCompile(`(^|\s)(\\*)\\#`)
+	detailsGroup        = regexp.MustCompile(`(\s)(\\*)\(([^(]*\))$`)
+	escapedDetailsGroup = regexp.MustCompile(`(\s)(\\*)\\\(([^(]*\))$`)
+)
+
+// escapeMarkup adds a backslash before every word-initial # and before the
+// ( of a trailing parenthesised group, so that parseMarkdownItem takes
+// neither for a tag or for task details.
+func escapeMarkup(s string) string {
+	s = hashWord.ReplaceAllString(s, `$1$2\#`)
+	return detailsGroup.ReplaceAllString(s, `$1$2\($3`)
+}
+
+// unescapeMarkup reverses escapeMarkup.
+func unescapeMarkup(s string) string {
+	s = escapedDetailsGroup.ReplaceAllString(s, `$1$2($3`)
+	return escapedHashWord.ReplaceAllString(s, `$1$2#`)
+}
+
+// parseTaskDetails parses the comma-separated details written by taskSuffix
+// into task. It reports false, leaving task untouched, if any part is unknown.
+func parseTaskDetails(details string, task *Task) bool {
+	parsed := *task
+	for _, part := range strings.Split(details, ", ") {
+		switch {
+		case strings.HasPrefix(part, "due "):
+			due, err := ParseDate(strings.TrimPrefix(part, "due "), now())
+			if err != nil {
+				return false
+			}
+			parsed.Due = due
+		case strings.HasPrefix(part, "repeats "):
+			rule, err := ParseRecurrence(strings.TrimPrefix(part, "repeats "))
+			if err != nil {
+				return false
+			}
+			parsed.Recurrence = rule
+		default:
+			p, err := ParsePriority(part)
+			if err != nil || p == PriorityNone {
+				return false
+			}
+			parsed.Priority = p
+		}
+	}
+	*task = parsed
+	return true
+}
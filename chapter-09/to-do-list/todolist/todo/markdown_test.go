@@ -0,0 +1,120 @@
+package todo
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	tasks := []Task{
+		{
+			ID:          1,
+			Description: "Pay rent",
+			Due:         day(2024, 11, 1),
+			Priority:    PriorityHigh,
+			Tags:        []string{"home", "money"},
+			Notes:       "Transfer before noon\nuse the joint account",
+			Recurrence:  Recurrence{Freq: Weekly, ByDay: []time.Weekday{time.Monday, time.Friday}},
+		},
+		{ID: 2, Description: "Go to gym", Completed: true},
+		{ID: 3, Description: "Call mom (about dinner)"},
+		{ID: 4, Description: "Fix bug #42", Tags: []string{"work"}},
+		{ID: 5, Description: `Write \#1 in C#`},
+		{ID: 6, Description: "Call dad (high)"},
+		{ID: 7, Description: "Buy milk (m)", Priority: PriorityLow},
+		{ID: 8, Description: `Print \(a) and (b) (c)`},
+		{ID: 9, Description: "Reply #,", Tags: []string{"mail"}},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportMarkdown(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	expectedText := "- [ ] Pay rent (due 2024-11-01, high, repeats FREQ=WEEKLY;BYDAY=MO,FR) #home #money\n" +
+		"  Transfer before noon\n" +
+		"  use the joint account\n" +
+		"- [x] Go to gym\n" +
+		"- [ ] Call mom \\(about dinner)\n" +
+		"- [ ] Fix bug \\#42 #work\n" +
+		"- [ ] Write \\\\#1 in C#\n" +
+		"- [ ] Call dad \\(high)\n" +
+		"- [ ] Buy milk \\(m) (low)\n" +
+		"- [ ] Print \\(a) and (b) \\(c)\n" +
+		"- [ ] Reply \\#, #mail\n"
+	if buf.String() != expectedText {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedText, buf.String())
+	}
+
+	imported, err := ImportMarkdown(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tasks, imported) {
+		t.Errorf("expected %+v, got %+v", tasks, imported)
+	}
+	// A lone # followed by punctuation is not an empty tag.
+	imported, _ = ImportMarkdown(strings.NewReader("- [ ] Reply #,\n"))
+	if len(imported) != 1 || imported[0].Description != "Reply #," || imported[0].Tags != nil {
+		t.Errorf("expected the description \"Reply #,\" without tags, got %+v", imported)
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	input := `# Groceries
+
+Some intro text.
+
+* [X] Milk
++ [ ] Bread (due 2024-02-29) #bakery
+    - [ ] nested item
+
+  not a note, separated by a blank line above
+- [] not a checklist item
+`
+	tasks, err := ImportMarkdown(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Task{
+		{ID: 1, Description: "Milk", Completed: true},
+		{ID: 2, Description: "Bread", Due: day(2024, 2, 29), Tags: []string{"bakery"}},
+		{ID: 3, Description: "nested item"},
+	}
+	if !reflect.DeepEqual(expected, tasks) {
+		t.Errorf("expected %+v, got %+v", expected, tasks)
+	}
+}
+
+func TestImportFileIntoList(t *testing.T) {
+	dir := t.TempDir()
+	l := NewList([]Task{{ID: 1, Description: "existing"}})
+
+	for _, name := range []string{"tasks.ics", "tasks.md"} {
+		filename := filepath.Join(dir, name)
+		if err := ExportFile(l.Tasks[:1], filename); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		imported, err := ImportFile(filename)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		l.Import(imported)
+	}
+	if got := ids(l.Tasks); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("expected IDs [1 2 3], got %v", got)
+	}
+	if _, err := l.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Tasks) != 2 {
+		t.Errorf("expected undo to remove the last import, got %v", l.Tasks)
+	}
+
+	if err := ExportFile(l.Tasks, filepath.Join(dir, "tasks.csv")); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority ranks how urgent a task is. The zero value means no priority.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// ParsePriority accepts the names returned by String as well as the short
+// forms l/m/h.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return PriorityNone, nil
+	case "low", "l":
+		return PriorityLow, nil
+	case "medium", "med", "m":
+		return PriorityMedium, nil
+	case "high", "h":
+		return PriorityHigh, nil
+	}
+	return PriorityNone, fmt.Errorf("invalid priority %q, expected low, medium or high", s)
+}
@@ -0,0 +1,358 @@
+package todo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a recurrence rule.
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// maxSearch bounds how many periods Next looks ahead before giving up, so a
+// rule such as "every 12 months on the 30th" starting in February cannot loop
+// forever.
+const maxSearch = 400
+
+// Recurrence is a subset of the iCalendar RRULE (RFC 5545): FREQ, INTERVAL,
+// BYDAY (DAILY and WEEKLY only), BYMONTHDAY (MONTHLY only), COUNT and UNTIL.
+// The task's due date plays the role of DTSTART. The zero value means the
+// task does not recur.
+//
+// As in RFC 5545, a monthly rule anchored on the 31st skips months that have
+// no 31st and a yearly rule on February 29 only fires in leap years; use
+// BYMONTHDAY=-1 for "last day of the month".
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	// Count is the number of occurrences left, including the current one.
+	// Zero means unlimited.
+	Count int
+	Until time.Time
+}
+
+// IsZero reports whether r is the empty rule.
+func (r Recurrence) IsZero() bool {
+	return r.Freq == 0
+}
+
+// ParseRecurrence parses an RRULE such as "FREQ=WEEKLY;BYDAY=MO,WE" (with or
+// without the "RRULE:" prefix) or one of the shorthands daily, weekly,
+// monthly, yearly and weekdays. "none" and the empty string give the zero rule.
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "none":
+		return Recurrence{}, nil
+	case "daily":
+		return Recurrence{Freq: Daily}, nil
+	case "weekly":
+		return Recurrence{Freq: Weekly}, nil
+	case "monthly":
+		return Recurrence{Freq: Monthly}, nil
+	case "yearly":
+		return Recurrence{Freq: Yearly}, nil
+	case "weekdays":
+		return Recurrence{Freq: Weekly, ByDay: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		}}, nil
+	}
+
+	var r Recurrence
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return Recurrence{}, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq, err = parseFrequency(value)
+		case "INTERVAL":
+			r.Interval, err = parsePositive(key, value)
+		case "COUNT":
+			r.Count, err = parsePositive(key, value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = fmt.Errorf("unsupported recurrence part %q", key)
+		}
+		if err != nil {
+			return Recurrence{}, err
+		}
+	}
+
+	if r.Freq == 0 {
+		return Recurrence{}, fmt.Errorf("recurrence %q has no FREQ", s)
+	}
+	if len(r.ByDay) > 0 && r.Freq != Daily && r.Freq != Weekly {
+		return Recurrence{}, fmt.Errorf("BYDAY is only supported with FREQ=DAILY or FREQ=WEEKLY")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly {
+		return Recurrence{}, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if r.Interval == 1 {
+		r.Interval = 0
+	}
+	return r, nil
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for f, name := range frequencyNames {
+		if name == value {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported FREQ %q", value)
+}
+
+func parsePositive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	// Only the date is kept; "20241231T235959Z" is treated as 20241231.
+	if len(value) > 8 {
+		value = value[:8]
+	}
+	until, err := time.ParseInLocation("20060102", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+	}
+	return until, nil
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		found := false
+		for wd, c := range weekdayCodes {
+			if c == code {
+				days = append(days, time.Weekday(wd))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid BYDAY %q", code)
+		}
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, v := range strings.Split(value, ",") {
+		d, err := strconv.Atoi(v)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %q", v)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// String returns the rule in RRULE form without the "RRULE:" prefix.
+func (r Recurrence) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var codes []string
+		for _, wd := range r.ByDay {
+			codes = append(codes, weekdayCodes[wd])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after from, which is normally
+// the due date of the current occurrence. It returns false when the series
+// has ended because of COUNT or UNTIL, or when no date matches the rule.
+func (r Recurrence) Next(from time.Time) (time.Time, bool) {
+	if r.IsZero() || r.Count == 1 {
+		return time.Time{}, false
+	}
+	from = startOfDay(from)
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var next time.Time
+	var ok bool
+	switch r.Freq {
+	case Daily:
+		next, ok = r.nextDaily(from, interval)
+	case Weekly:
+		next, ok = r.nextWeekly(from, interval)
+	case Monthly:
+		next, ok = r.nextMonthly(from, interval)
+	case Yearly:
+		next, ok = nextYearly(from, interval)
+	}
+	if !ok || (!r.Until.IsZero() && next.After(r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// advance returns the rule carried by the next occurrence, with COUNT
+// reduced by the occurrence just completed. Slices are shared with r, so
+// callers copy the task holding the result.
+func (r Recurrence) advance() Recurrence {
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+func (r Recurrence) nextDaily(from time.Time, interval int) (time.Time, bool) {
+	d := from
+	// Stepping by interval days cycles through the weekdays within 7 steps.
+	for i := 0; i < 7; i++ {
+		d = d.AddDate(0, 0, interval)
+		if r.matchesDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Recurrence) nextWeekly(from time.Time, interval int) (time.Time, bool) {
+	if len(r.ByDay) == 0 {
+		return from.AddDate(0, 0, 7*interval), true
+	}
+	// Weeks start on Monday (WKST=MO); only every interval-th week counts.
+	start := weekStart(from)
+	for i := 1; i <= 7*interval+7; i++ {
+		d := from.AddDate(0, 0, i)
+		week := daysBetween(start, weekStart(d)) / 7
+		if week%interval == 0 && r.matchesDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Recurrence) nextMonthly(from time.Time, interval int) (time.Time, bool) {
+	monthDays := r.ByMonthDay
+	if len(monthDays) == 0 {
+		monthDays = []int{from.Day()}
+	}
+	for k := 0; k < maxSearch; k += interval {
+		first := time.Date(from.Year(), from.Month()+time.Month(k), 1, 0, 0, 0, 0, from.Location())
+		n := daysIn(first.Year(), first.Month())
+
+		var candidates []int
+		for _, d := range monthDays {
+			if d < 0 {
+				d = n + d + 1
+			}
+			if d >= 1 && d <= n {
+				candidates = append(candidates, d)
+			}
+		}
+		sort.Ints(candidates)
+		for _, d := range candidates {
+			date := first.AddDate(0, 0, d-1)
+			if date.After(from) {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func nextYearly(from time.Time, interval int) (time.Time, bool) {
+	for k := interval; k < maxSearch; k += interval {
+		date := time.Date(from.Year()+k, from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+		// time.Date normalises Feb 29 to Mar 1 in common years; skip those.
+		if date.Month() == from.Month() && date.Day() == from.Day() {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Recurrence) matchesDay(d time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if d.Weekday() == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func weekStart(d time.Time) time.Time {
+	offset := (int(d.Weekday()) + 6) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+// daysBetween counts calendar days from a to b, ignoring DST shifts.
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustParseRecurrence(t *testing.T, s string) Recurrence {
+	t.Helper()
+	r, err := ParseRecurrence(s)
+	if err != nil {
+		t.Fatalf("ParseRecurrence(%q): %v", s, err)
+	}
+	return r
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		rule     string
+		from     time.Time
+		expected []time.Time
+	}{
+		{"daily", day(2024, 2, 28), []time.Time{day(2024, 2, 29), day(2024, 3, 1)}},
+		{"daily", day(2023, 2, 28), []time.Time{day(2023, 3, 1)}},
+		{"FREQ=DAILY;INTERVAL=3", day(2024, 12, 30), []time.Time{day(2025, 1, 2), day(2025, 1, 5)}},
+		{"FREQ=DAILY;BYDAY=SA,SU", day(2024, 10, 16), []time.Time{day(2024, 10, 19), day(2024, 10, 20), day(2024, 10, 26)}},
+		{"weekly", day(2024, 12, 28), []time.Time{day(2025, 1, 4)}},
+		{"weekdays", day(2024, 10, 18), []time.Time{day(2024, 10, 21), day(2024, 10, 22)}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", day(2024, 10, 14), []time.Time{day(2024, 10, 16), day(2024, 10, 21)}},
+		// Every other week: from Wednesday the next Monday is in an "off" week.
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", day(2024, 10, 16), []time.Time{day(2024, 10, 28), day(2024, 10, 30), day(2024, 11, 11)}},
+		{"monthly", day(2024, 1, 15), []time.Time{day(2024, 2, 15), day(2024, 3, 15)}},
+		// The 31st skips months without one.
+		{"monthly", day(2024, 1, 31), []time.Time{day(2024, 3, 31), day(2024, 5, 31), day(2024, 7, 31), day(2024, 8, 31)}},
+		// The 29th only exists in February of leap years.
+		{"monthly", day(2023, 1, 29), []time.Time{day(2023, 3, 29)}},
+		{"monthly", day(2024, 1, 29), []time.Time{day(2024, 2, 29), day(2024, 3, 29)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", day(2024, 1, 31), []time.Time{day(2024, 2, 29), day(2024, 3, 31), day(2024, 4, 30)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", day(2023, 1, 31), []time.Time{day(2023, 2, 28)}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", day(2024, 12, 15), []time.Time{day(2025, 1, 1), day(2025, 1, 15)}},
+		// February has no 30th, so that period is skipped.
+		{"FREQ=MONTHLY;INTERVAL=3", day(2024, 11, 30), []time.Time{day(2025, 5, 30), day(2025, 8, 30)}},
+		{"yearly", day(2024, 2, 29), []time.Time{day(2028, 2, 29)}},
+		{"yearly", day(2096, 2, 29), []time.Time{day(2104, 2, 29)}},
+		{"yearly", day(2024, 12, 31), []time.Time{day(2025, 12, 31)}},
+	}
+	for _, tt := range tests {
+		r := mustParseRecurrence(t, tt.rule)
+		from := tt.from
+		for _, want := range tt.expected {
+			got, ok := r.Next(from)
+			if !ok || !got.Equal(want) {
+				t.Errorf("%s after %s: expected %s, got %s (ok=%v)",
+					tt.rule, from.Format(DateLayout), want.Format(DateLayout), got.Format(DateLayout), ok)
+				break
+			}
+			from = got
+		}
+	}
+}
+
+func TestRecurrenceEnds(t *testing.T) {
+	r := mustParseRecurrence(t, "FREQ=DAILY;UNTIL=20241020")
+	if _, ok := r.Next(day(2024, 10, 19)); !ok {
+		t.Error("expected an occurrence on the UNTIL date")
+	}
+	if _, ok := r.Next(day(2024, 10, 20)); ok {
+		t.Error("expected no occurrence after UNTIL")
+	}
+
+	r = mustParseRecurrence(t, "FREQ=DAILY;COUNT=2")
+	if _, ok := r.Next(day(2024, 10, 19)); !ok {
+		t.Error("expected a second occurrence")
+	}
+	if _, ok := r.advance().Next(day(2024, 10, 20)); ok {
+		t.Error("expected the series to end after COUNT occurrences")
+	}
+
+	// Every 12 months on the 30th starting in February never matches.
+	r = Recurrence{Freq: Monthly, Interval: 12, ByMonthDay: []int{30}}
+	if _, ok := r.Next(day(2024, 2, 1)); ok {
+		t.Error("expected no occurrence for an impossible rule")
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	valid := map[string]string{
+		"daily":                                 "FREQ=DAILY",
+		"weekdays":                              "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"RRULE:FREQ=WEEKLY;INTERVAL=1;BYDAY=SU": "FREQ=WEEKLY;BYDAY=SU",
+		"freq=monthly;interval=2;bymonthday=-1": "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1",
+		"FREQ=YEARLY;COUNT=5":                   "FREQ=YEARLY;COUNT=5",
+		"FREQ=DAILY;UNTIL=20241231T235959Z":     "FREQ=DAILY;UNTIL=20241231",
+		"none":                                  "",
+	}
+	for input, expected := range valid {
+		r := mustParseRecurrence(t, input)
+		if r.String() != expected {
+			t.Errorf("ParseRecurrence(%q).String() = %q, expected %q", input, r.String(), expected)
+		}
+		again := mustParseRecurrence(t, r.String())
+		if !reflect.DeepEqual(r, again) {
+			t.Errorf("round trip of %q: expected %+v, got %+v", input, r, again)
+		}
+	}
+
+	invalid := []string{
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ",
+	}
+	for _, input := range invalid {
+		if _, err := ParseRecurrence(input); err == nil {
+			t.Errorf("ParseRecurrence(%q): expected error", input)
+		}
+	}
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	l := NewList([]Task{{
+		ID:          1,
+		Description: "Pay rent",
+		Due:         day(2024, 1, 31),
+		Tags:        []string{"home"},
+		Recurrence:  mustParseRecurrence(t, "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2"),
+	}})
+	before := cloneTasks(l.Tasks)
+
+	next, err := l.Complete(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil {
+		t.Fatal("expected a next occurrence")
+	}
+	expected := Task{
+		ID:          2,
+		Description: "Pay rent",
+		Due:         day(2024, 2, 29),
+		Tags:        []string{"home"},
+		Recurrence:  Recurrence{Freq: Monthly, ByMonthDay: []int{-1}, Count: 1},
+	}
+	if !reflect.DeepEqual(*next, expected) || !reflect.DeepEqual(l.Tasks[1], expected) {
+		t.Errorf("expected %+v, got %+v", expected, l.Tasks[1])
+	}
+	if !l.Tasks[0].Completed {
+		t.Error("expected the first occurrence to be completed")
+	}
+
+	// COUNT is exhausted, so the last occurrence spawns nothing.
+	next, err = l.Complete(2)
+	if err != nil || next != nil || len(l.Tasks) != 2 {
+		t.Errorf("expected the series to end, got %v, %v, %v", next, err, l.Tasks)
+	}
+
+	// Undoing both completions removes the spawned task as well.
+	l.Undo()
+	l.Undo()
+	if !reflect.DeepEqual(l.Tasks, before) {
+		t.Errorf("expected %v after undo, got %v", before, l.Tasks)
+	}
+}
+
+func TestCompleteRecurringTaskWithoutDue(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 10, 16, 20, 0, 0, 0, time.Local) }
+
+	l := NewList([]Task{{ID: 1, Description: "Water plants", Recurrence: Recurrence{Freq: Daily}}})
+	next, err := l.Complete(1)
+	if err != nil || next == nil {
+		t.Fatalf("expected a next occurrence, got %v, %v", next, err)
+	}
+	if !next.Due.Equal(day(2024, 10, 17)) {
+		t.Errorf("expected next due 2024-10-17, got %s", next.Due.Format(DateLayout))
+	}
+}
+
+func TestLoadAndSaveRecurrence(t *testing.T) {
+	tasks := []Task{{
+		ID:          1,
+		Description: "Stand-up",
+		Due:         day(2024, 10, 14),
+		Recurrence:  mustParseRecurrence(t, "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20241231"),
+	}}
+	filename := filepath.Join(t.TempDir(), "tasks.txt")
+	if err := SaveTasksToFile(tasks, filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTasksFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tasks, loaded) {
+		t.Errorf("expected %+v, got %+v", tasks, loaded)
+	}
+}
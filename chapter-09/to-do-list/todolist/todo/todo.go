@@ -3,18 +3,50 @@ package todo
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// DateLayout is the format used for due dates on the command line and in the store.
+const DateLayout = "2006-01-02"
+
 type Task struct {
 	ID          int
 	Description string
 	Completed   bool
+	Due         time.Time
+	Priority    Priority
+	Tags        []string
+	Notes       string
+	Recurrence  Recurrence
+}
+
+// HasDue reports whether the task has a due date set.
+func (t Task) HasDue() bool {
+	return !t.Due.IsZero()
+}
+
+// HasTag reports whether the task carries the given tag, ignoring case.
+func (t Task) HasTag(tag string) bool {
+	for _, tg := range t.Tags {
+		if strings.EqualFold(tg, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue reports whether an open task was due before the day of now.
+func (t Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.HasDue() && t.Due.Before(startOfDay(now))
 }
 
 func AddTask(tasks *[]Task, description string) {
 	newTask := Task{
-		ID:          len(*tasks) + 1,
+		ID:          nextID(*tasks, 1),
 		Description: description,
 		Completed:   false,
 	}
@@ -22,94 +54,277 @@ func AddTask(tasks *[]Task, description string) {
 	fmt.Println("Task added!")
 }
 func ListTasks(tasks []Task) {
+	WriteTasks(os.Stdout, tasks)
+}
+
+// WriteTasks writes one line per task to w, followed by its notes.
+func WriteTasks(w io.Writer, tasks []Task) {
 	if len(tasks) == 0 {
-		fmt.Println("No task found")
+		fmt.Fprintln(w, "No task found")
 		return
 	}
 	for _, task := range tasks {
-		status := "[ ]"
-		if task.Completed {
-			status = "[x]"
+		fmt.Fprintln(w, FormatTask(task))
+		if task.Notes != "" {
+			fmt.Fprintf(w, "    %s\n", task.Notes)
 		}
-		fmt.Printf("%d %s %s\n", task.ID, status, task.Description)
 	}
 }
 
-func CompleteTask(tasks *[]Task, id int) {
+// FormatTask renders a task as a single line, e.g.
+// "3 [ ] Pay rent (due 2024-11-01, high) #home".
+func FormatTask(task Task) string {
+	status := "[ ]"
+	if task.Completed {
+		status = "[x]"
+	}
+	return fmt.Sprintf("%d %s %s%s", task.ID, status, task.Description, taskSuffix(task))
+}
+
+// taskSuffix renders the due date, priority and recurrence in parentheses
+// followed by the tags, e.g. " (due 2024-11-01, high) #home".
+func taskSuffix(task Task) string {
+	var suffix string
+	var details []string
+	if task.HasDue() {
+		details = append(details, "due "+task.Due.Format(DateLayout))
+	}
+	if task.Priority != PriorityNone {
+		details = append(details, task.Priority.String())
+	}
+	if !task.Recurrence.IsZero() {
+		details = append(details, "repeats "+task.Recurrence.String())
+	}
+	if len(details) > 0 {
+		suffix += " (" + strings.Join(details, ", ") + ")"
+	}
+	for _, tag := range task.Tags {
+		suffix += " #" + tag
+	}
+	return suffix
+}
+
+func CompleteTask(tasks *[]Task, id int) error {
 	for i, task := range *tasks {
 		if task.ID == id {
 			(*tasks)[i].Completed = true
 			fmt.Println("Task marked as complete")
-			return
+			return nil
 		}
 	}
+	return fmt.Errorf("task %d not found", id)
+}
+
+// normalizeTag strips the optional leading '#' and characters that would
+// break the store format.
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.Map(func(r rune) rune {
+		if r == ',' || r == '\t' || r == '\n' || r == ' ' {
+			return -1
+		}
+		return r
+	}, tag)
+}
+
+// ParseDate parses a due date given as YYYY-MM-DD, "today", "tomorrow" or
+// "+N" (days from now). "none" and the empty string clear the date.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "none":
+		return time.Time{}, nil
+	case "today":
+		return startOfDay(now), nil
+	case "tomorrow":
+		return startOfDay(now).AddDate(0, 0, 1), nil
+	}
+	if strings.HasPrefix(s, "+") {
+		days, err := strconv.Atoi(s[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", s)
+		}
+		return startOfDay(now).AddDate(0, 0, days), nil
+	}
+	due, err := time.ParseInLocation(DateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return due, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
 
 func LoadTasksFromFile(filename string) ([]Task, error) {
+	tasks, _, err := readStore(filename)
+	return tasks, err
+}
+
+// nextIDHeader prefixes the store line that remembers the next task ID, so
+// that IDs of deleted tasks are not handed out again.
+const nextIDHeader = "#next-id\t"
+
+// readStore loads the tasks in filename along with the stored next ID, which
+// is 0 when the file has no header.
+func readStore(filename string) ([]Task, int, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []Task{}, nil
+			return []Task{}, 0, nil
 		}
-		return nil, err
+		return nil, 0, err
 	}
 	defer file.Close()
 
 	var tasks []Task
+	next := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if strings.HasPrefix(line, nextIDHeader) {
+			next, _ = strconv.Atoi(strings.TrimPrefix(line, nextIDHeader))
+			continue
+		}
 		var task Task
-		var completedStr string
-		_, err := fmt.Sscan(line, &task.ID)
-		if err != nil {
+		var ok bool
+		if strings.Contains(line, "\t") {
+			task, ok = parseTaskLine(line)
+		} else {
+			task, ok = parseLegacyLine(line)
+		}
+		if !ok {
 			continue
 		}
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return tasks, next, nil
+}
 
-		parts := []rune(line)
-		index := 0
-		for i := 0; i < len(parts); i++ {
-			if parts[i] == ' ' {
-				index = i + 1
-				break
-			}
+// parseTaskLine reads a tab-separated record as written by SaveTasksToFile:
+// id, description, completed, due, priority, tags, notes, recurrence.
+func parseTaskLine(line string) (Task, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return Task{}, false
+	}
+	var task Task
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Task{}, false
+	}
+	task.ID = id
+	task.Description = unescapeField(fields[1])
+	task.Completed = fields[2] == "true"
+
+	if len(fields) > 3 && fields[3] != "" {
+		due, err := time.ParseInLocation(DateLayout, fields[3], time.Local)
+		if err == nil {
+			task.Due = due
 		}
+	}
+	if len(fields) > 4 {
+		task.Priority, _ = ParsePriority(fields[4])
+	}
+	if len(fields) > 5 && fields[5] != "" {
+		task.Tags = strings.Split(fields[5], ",")
+	}
+	if len(fields) > 6 {
+		task.Notes = unescapeField(fields[6])
+	}
+	if len(fields) > 7 {
+		task.Recurrence, _ = ParseRecurrence(fields[7])
+	}
+	return task, true
+}
+
+// parseLegacyLine reads the original space-separated "id description completed"
+// format so that older task files keep loading.
+func parseLegacyLine(line string) (Task, bool) {
+	var task Task
+	var completedStr string
+	_, err := fmt.Sscan(line, &task.ID)
+	if err != nil {
+		return Task{}, false
+	}
 
-		indexCompleted := 0
-		for i := len(parts) - 1; i >= 0; i-- {
-			if parts[i] == ' ' {
-				indexCompleted = i + 1
-				break
-			}
+	parts := []rune(line)
+	index := 0
+	for i := 0; i < len(parts); i++ {
+		if parts[i] == ' ' {
+			index = i + 1
+			break
 		}
+	}
 
-		if index < indexCompleted {
-			task.Description = string(parts[index : indexCompleted-1])
+	indexCompleted := 0
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == ' ' {
+			indexCompleted = i + 1
+			break
 		}
+	}
 
-		completedStr = string(parts[indexCompleted:])
+	if index < indexCompleted {
+		task.Description = string(parts[index : indexCompleted-1])
+	}
 
-		if completedStr == "true" {
-			task.Completed = true
-		} else {
-			task.Completed = false
-		}
+	completedStr = string(parts[indexCompleted:])
 
-		tasks = append(tasks, task)
+	if completedStr == "true" {
+		task.Completed = true
+	} else {
+		task.Completed = false
 	}
-	return tasks, nil
+	return task, true
 }
+
 func SaveTasksToFile(tasks []Task, filename string) error {
+	return writeStore(tasks, 0, filename)
+}
+
+// writeStore saves tasks to filename, preceded by the next ID header when
+// next is positive.
+func writeStore(tasks []Task, next int, filename string) (err error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if next > 0 {
+		if _, err := fmt.Fprintf(file, "%s%d\n", nextIDHeader, next); err != nil {
+			return err
+		}
+	}
 	for _, task := range tasks {
-		_, err = fmt.Fprintf(file, "%d %s %t\n", task.ID, task.Description, task.Completed)
+		due := ""
+		if task.HasDue() {
+			due = task.Due.Format(DateLayout)
+		}
+		_, err = fmt.Fprintf(file, "%d\t%s\t%t\t%s\t%s\t%s\t%s\t%s\n",
+			task.ID, escapeField(task.Description), task.Completed,
+			due, task.Priority, strings.Join(task.Tags, ","), escapeField(task.Notes),
+			task.Recurrence)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+var (
+	fieldEscaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+	fieldUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+)
+
+func escapeField(s string) string   { return fieldEscaper.Replace(s) }
+func unescapeField(s string) string { return fieldUnescaper.Replace(s) }
@@ -2,8 +2,10 @@ package todo
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestAddTask(t *testing.T) {
@@ -19,18 +21,30 @@ func TestAddTask(t *testing.T) {
 	}
 }
 
+func TestAddTaskAfterGap(t *testing.T) {
+	tasks := []Task{{ID: 1, Description: "a"}, {ID: 3, Description: "c"}}
+	AddTask(&tasks, "d")
+	if tasks[2].ID != 4 {
+		t.Errorf("AddTask reused an ID, tasks: %v", tasks)
+	}
+}
+
 func TestCompleteTask(t *testing.T) {
 	tasks := []Task{
 		{ID: 1, Description: "Buy groceries", Completed: false},
 		{ID: 2, Description: "Go to gym", Completed: false},
 	}
 
-	CompleteTask(&tasks, 1)
+	if err := CompleteTask(&tasks, 1); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
 
 	if tasks[0].Completed != true || tasks[1].Completed != false {
 		t.Errorf("CompleteTask failed, tasks: %v", tasks)
 	}
-	CompleteTask(&tasks, 3) // should do nothing
+	if err := CompleteTask(&tasks, 3); err == nil {
+		t.Error("CompleteTask should fail for an unknown ID")
+	}
 	if tasks[0].Completed != true || tasks[1].Completed != false {
 		t.Errorf("CompleteTask failed, tasks: %v", tasks)
 	}
@@ -59,3 +73,74 @@ func TestLoadAndSaveTasks(t *testing.T) {
 
 	os.Remove(filename) // cleanup
 }
+
+func TestLoadAndSaveTaskDetails(t *testing.T) {
+	tasks := []Task{
+		{
+			ID:          1,
+			Description: "Pay\trent",
+			Due:         time.Date(2024, 11, 1, 0, 0, 0, 0, time.Local),
+			Priority:    PriorityHigh,
+			Tags:        []string{"home", "money"},
+			Notes:       "Transfer before noon\nuse the joint account",
+		},
+		{ID: 2, Description: "Go to gym", Completed: true},
+	}
+
+	filename := filepath.Join(t.TempDir(), "tasks.txt")
+	if err := SaveTasksToFile(tasks, filename); err != nil {
+		t.Fatalf("SaveTasksToFile failed: %v", err)
+	}
+	loadedTasks, err := LoadTasksFromFile(filename)
+	if err != nil {
+		t.Fatalf("LoadTasksFromFile failed: %v", err)
+	}
+	if !reflect.DeepEqual(tasks, loadedTasks) {
+		t.Errorf("LoadTasksFromFile failed: expected %v, got %v", tasks, loadedTasks)
+	}
+}
+
+func TestLoadLegacyTasks(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.txt")
+	if err := os.WriteFile(filename, []byte("1 Buy groceries false\n2 Go to gym true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadedTasks, err := LoadTasksFromFile(filename)
+	if err != nil {
+		t.Fatalf("LoadTasksFromFile failed: %v", err)
+	}
+	expected := []Task{
+		{ID: 1, Description: "Buy groceries"},
+		{ID: 2, Description: "Go to gym", Completed: true},
+	}
+	if !reflect.DeepEqual(expected, loadedTasks) {
+		t.Errorf("LoadTasksFromFile failed: expected %v, got %v", expected, loadedTasks)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 2, 28, 18, 0, 0, 0, time.Local)
+	tests := []struct {
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"2024-03-05", time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), false},
+		{"today", time.Date(2024, 2, 28, 0, 0, 0, 0, time.Local), false},
+		{"tomorrow", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local), false},
+		{"+3", time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local), false},
+		{"none", time.Time{}, false},
+		{"05/03/2024", time.Time{}, true},
+		{"+x", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.input, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("ParseDate(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -1,16 +1,54 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"webserver/middleware"
 	"webserver/server"
 )
 
 func main() {
-	http.HandleFunc("/", server.HomeHandler)
-	http.HandleFunc("/about", server.AboutHandler)
-	http.HandleFunc("/posts", server.PostsHandler)
-	fmt.Println("Server is starting at port 8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	cfg, err := server.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Error reading configuration: %v", err)
+	}
+	cfg.RegisterFlags(flag.CommandLine)
+	postsFile := flag.String("posts-file", "", "JSON file to store posts in (in memory if empty)")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	var posts server.PostRepository = server.NewMemoryPostRepository()
+	if *postsFile != "" {
+		repo, err := server.NewFilePostRepository(*postsFile)
+		if err != nil {
+			log.Fatalf("Error loading posts: %v", err)
+		}
+		posts = repo
+	}
+
+	srv := server.New(posts)
+	handler := middleware.Chain(srv,
+		middleware.RequestID,
+		middleware.Logger(logger),
+		middleware.RecoverWith(logger, http.HandlerFunc(srv.InternalError)),
+		middleware.CORS(middleware.CORSOptions{AllowedOrigins: []string{"*"}, MaxAge: 600}),
+		middleware.Gzip,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("server starting", "addr", cfg.Addr, "tls", cfg.TLS())
+	if err := server.ListenAndServe(ctx, cfg, handler); err != nil {
+		logger.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("server stopped")
 }
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSOptions configures CORS. An AllowedOrigins entry of "*" allows any
+// origin. Empty method and header lists fall back to common defaults.
+type CORSOptions struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int // seconds a preflight response may be cached
+}
+
+// CORS answers preflight requests and adds Access-Control-* headers to
+// responses for allowed origins. Requests from other origins are passed
+// through without CORS headers, so browsers will block them.
+func CORS(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete}
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type", "Authorization", RequestIDHeader}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || !originAllowed(opts.AllowedOrigins, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			if contains(opts.AllowedOrigins, "*") && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", allowMethods)
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+				if opts.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
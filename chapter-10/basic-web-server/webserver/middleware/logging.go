@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logger writes one structured log record per request with the method, path,
+// status, latency, response size and, when RequestID runs first, the request
+// ID.
+func Logger(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			attrs := []any{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.Status()),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int("bytes", rec.bytes),
+				slog.String("remote", r.RemoteAddr),
+			}
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			logger.InfoContext(r.Context(), "request", attrs...)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusTeapot)
+	io.WriteString(w, "hello")
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Chain(http.HandlerFunc(hello), mark("a"), mark("b"), mark("c"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if strings.Join(order, "") != "abc" {
+		t.Errorf("expected middleware to run in order abc, got %v", order)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := Chain(http.HandlerFunc(hello), RequestID, Logger(logger))
+
+	req := httptest.NewRequest(http.MethodPost, "/posts?x=1", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid log record %q: %v", buf.String(), err)
+	}
+	expected := map[string]any{
+		"msg":        "request",
+		"method":     "POST",
+		"path":       "/posts",
+		"status":     float64(http.StatusTeapot),
+		"bytes":      float64(5),
+		"request_id": "abc-123",
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Errorf("log field %s: expected %v, got %v", k, v, record[k])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("log record has no latency")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(seen) != 32 || recorder.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("expected a generated ID in context and response, got %q and %q", seen, recorder.Header().Get(RequestIDHeader))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "from-client")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if seen != "from-client" {
+		t.Errorf("expected the client ID to be kept, got %q", seen)
+	}
+
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if seen == "bad id\n" || len(seen) != 32 {
+		t.Errorf("expected an invalid client ID to be replaced, got %q", seen)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := Recover(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected %d, got %d", http.StatusInternalServerError, recorder.Code)
+	}
+	if !strings.Contains(buf.String(), `"panic":"boom"`) {
+		t.Errorf("expected the panic to be logged, got %q", buf.String())
+	}
+
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected a plain-text body, got %q", ct)
+	}
+
+	custom := RecoverWith(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"error":"boom"}`)
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	recorder = httptest.NewRecorder()
+	custom.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusInternalServerError || recorder.Body.String() != `{"error":"boom"}` {
+		t.Errorf("expected the custom response, got %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected ErrAbortHandler to propagate, got %v", v)
+		}
+	}()
+	Recover(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestCORS(t *testing.T) {
+	h := CORS(CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 60})(http.HandlerFunc(hello))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/posts", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, preflight)
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("preflight: expected %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+	for header, expected := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, HEAD, POST, PUT, DELETE",
+		"Access-Control-Max-Age":       "60",
+	} {
+		if got := recorder.Header().Get(header); got != expected {
+			t.Errorf("preflight %s: expected %q, got %q", header, expected, got)
+		}
+	}
+
+	other := httptest.NewRequest(http.MethodGet, "/posts", nil)
+	other.Header.Set("Origin", "https://evil.example.com")
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, other)
+	if recorder.Code != http.StatusTeapot || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin: unexpected response %d %v", recorder.Code, recorder.Header())
+	}
+
+	wildcard := CORS(CORSOptions{AllowedOrigins: []string{"*"}})(http.HandlerFunc(hello))
+	recorder = httptest.NewRecorder()
+	wildcard.ServeHTTP(recorder, other)
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("wildcard: expected *, got %q", recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestGzip(t *testing.T) {
+	body := strings.Repeat("compress me ", 100)
+	h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, got headers %v", recorder.Header())
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("expected the content type to be sniffed from the plain body, got %q", recorder.Header().Get("Content-Type"))
+	}
+	zr, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := io.ReadAll(zr)
+	if string(plain) != body {
+		t.Errorf("unexpected decompressed body %q", plain)
+	}
+
+	for _, accept := range []string{"", "br", "gzip;q=0"} {
+		req.Header.Set("Accept-Encoding", accept)
+		recorder = httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		if recorder.Header().Get("Content-Encoding") != "" || recorder.Body.String() != body {
+			t.Errorf("Accept-Encoding %q: expected an uncompressed body", accept)
+		}
+	}
+
+	noContent := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder = httptest.NewRecorder()
+	noContent.ServeHTTP(recorder, req)
+	if recorder.Header().Get("Content-Encoding") != "" || recorder.Body.Len() != 0 {
+		t.Errorf("204: expected no encoding and no body, got %v %q", recorder.Header(), recorder.Body.String())
+	}
+}
+
+func TestGzipServeContent(t *testing.T) {
+	body := strings.Repeat("compress me ", 100)
+	h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Header().Get("Content-Encoding") != "gzip" || recorder.Header().Get("ETag") != `W/"v1"` {
+		t.Errorf("expected a gzipped body with a weak ETag, got headers %v", recorder.Header())
+	}
+
+	// A range is served from the identity encoding, untouched.
+	req.Header.Set("Range", "bytes=0-9")
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusPartialContent || recorder.Header().Get("Content-Encoding") != "" ||
+		recorder.Header().Get("ETag") != `"v1"` || recorder.Body.String() != body[:10] {
+		t.Errorf("206: expected the plain range, got %d %v %q", recorder.Code, recorder.Header(), recorder.Body.String())
+	}
+
+	// The weak ETag still revalidates.
+	req.Header.Del("Range")
+	req.Header.Set("If-None-Match", `W/"v1"`)
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("expected %d for a matching weak ETag, got %d", http.StatusNotModified, recorder.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover turns a panic in a handler into a plain-text 500 response and logs
+// it with the stack trace. http.ErrAbortHandler is re-panicked, as net/http
+// expects.
+func Recover(logger *slog.Logger) Middleware {
+	return RecoverWith(logger, nil)
+}
+
+// RecoverWith is like Recover but lets onPanic write the 500 response, e.g.
+// in the error format of an API. A nil onPanic writes plain text.
+func RecoverWith(logger *slog.Logger, onPanic http.Handler) Middleware {
+	if onPanic == nil {
+		onPanic = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		})
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				logger.ErrorContext(r.Context(), "panic serving request",
+					slog.Any("panic", v),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("request_id", RequestIDFromContext(r.Context())),
+					slog.String("stack", string(debug.Stack())),
+				)
+				// If the handler already started the response there is
+				// nothing sensible left to send.
+				if rec.status == 0 {
+					onPanic.ServeHTTP(w, r)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config holds the listener settings of the HTTP server.
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// once shutdown has started.
+	ShutdownTimeout time.Duration
+	// CertFile and KeyFile enable HTTPS when both are set.
+	CertFile string
+	KeyFile  string
+}
+
+// DefaultConfig returns the settings used when nothing is configured.
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   15 * time.Second,
+	}
+}
+
+// ConfigFromEnv starts from DefaultConfig and overrides any setting given in
+// the WEBSERVER_* environment variables. Durations use time.ParseDuration
+// syntax, such as "30s".
+func ConfigFromEnv() (Config, error) {
+	c := DefaultConfig()
+	texts := map[string]*string{
+		"WEBSERVER_ADDR":     &c.Addr,
+		"WEBSERVER_TLS_CERT": &c.CertFile,
+		"WEBSERVER_TLS_KEY":  &c.KeyFile,
+	}
+	for name, field := range texts {
+		if v, ok := os.LookupEnv(name); ok {
+			*field = v
+		}
+	}
+	durations := map[string]*time.Duration{
+		"WEBSERVER_READ_TIMEOUT":        &c.ReadTimeout,
+		"WEBSERVER_READ_HEADER_TIMEOUT": &c.ReadHeaderTimeout,
+		"WEBSERVER_WRITE_TIMEOUT":       &c.WriteTimeout,
+		"WEBSERVER_IDLE_TIMEOUT":        &c.IdleTimeout,
+		"WEBSERVER_SHUTDOWN_TIMEOUT":    &c.ShutdownTimeout,
+	}
+	for name, field := range durations {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+		*field = d
+	}
+	return c, nil
+}
+
+// RegisterFlags defines command-line flags for every setting, using the
+// current values as defaults so that flags take precedence over the
+// environment.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "address to listen on")
+	fs.DurationVar(&c.ReadTimeout, "read-timeout", c.ReadTimeout, "maximum time to read a whole request")
+	fs.DurationVar(&c.ReadHeaderTimeout, "read-header-timeout", c.ReadHeaderTimeout, "maximum time to read request headers")
+	fs.DurationVar(&c.WriteTimeout, "write-timeout", c.WriteTimeout, "maximum time to write a response")
+	fs.DurationVar(&c.IdleTimeout, "idle-timeout", c.IdleTimeout, "how long idle keep-alive connections are kept open")
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "how long to wait for in-flight requests on shutdown")
+	fs.StringVar(&c.CertFile, "tls-cert", c.CertFile, "TLS certificate file (enables HTTPS with -tls-key)")
+	fs.StringVar(&c.KeyFile, "tls-key", c.KeyFile, "TLS private key file")
+}
+
+// Validate reports settings that cannot work together.
+func (c Config) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("both a TLS certificate and key are required for HTTPS")
+	}
+	for name, d := range map[string]time.Duration{
+		"read timeout":        c.ReadTimeout,
+		"read header timeout": c.ReadHeaderTimeout,
+		"write timeout":       c.WriteTimeout,
+		"idle timeout":        c.IdleTimeout,
+		"shutdown timeout":    c.ShutdownTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// TLS reports whether the server should serve HTTPS.
+func (c Config) TLS() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxBodyBytes limits the size of JSON request bodies.
+const maxBodyBytes = 64 << 10
+
+// Error codes used in the "code" field of error responses. Clients should
+// branch on the code rather than on the message.
+const (
+	codeBadRequest       = "bad_request"
+	codeInvalidJSON      = "invalid_json"
+	codeBodyTooLarge     = "body_too_large"
+	codeValidation       = "validation_failed"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeInternal         = "internal_error"
+)
+
+// apiError is the body of every error response from the JSON API, wrapped
+// as {"error": {...}}. Fields maps JSON field names to what is wrong with
+// them.
+type apiError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorResponse{apiError{Code: code, Message: message}})
+}
+
+func writeFieldErrors(w http.ResponseWriter, status int, code, message string, fields map[string]string) {
+	writeJSON(w, status, errorResponse{apiError{Code: code, Message: message, Fields: fields}})
+}
+
+func writeRepoError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrPostNotFound) {
+		writeError(w, http.StatusNotFound, codeNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, codeInternal, "could not store post")
+}
+
+// methodNotAllowed answers requests to a known API path with an unsupported
+// method, listing the allowed ones.
+func methodNotAllowed(allow string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed", r.Method))
+	}
+}
+
+// notFound answers requests to paths that have no handler.
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, codeNotFound, fmt.Sprintf("no resource at %s", r.URL.Path))
+}
+
+// pageMethodNotAllowed is methodNotAllowed for HTML pages, answering in
+// plain text.
+func pageMethodNotAllowed(allow string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// InternalError answers a request whose handler failed unexpectedly, such
+// as after a panic: in plain text for the HTML pages and as a JSON error
+// everywhere else. Use it with middleware.RecoverWith.
+func (s *Server) InternalError(w http.ResponseWriter, r *http.Request) {
+	if s.isPage(r) {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, codeInternal, "internal server error")
+}
+
+// decodeJSON reads a single JSON object from the request body into v and
+// validates it. The body may not exceed maxBodyBytes nor contain fields that
+// v does not have. On failure it writes the error response and returns
+// false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("body must contain a single JSON object")
+	}
+	if err != nil {
+		writeDecodeError(w, err)
+		return false
+	}
+
+	if fields := validate(v); len(fields) > 0 {
+		writeFieldErrors(w, http.StatusUnprocessableEntity, codeValidation, "request has invalid fields", fields)
+		return false
+	}
+	return true
+}
+
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, codeBodyTooLarge,
+			fmt.Sprintf("body must not be larger than %d bytes", tooLarge.Limit))
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		writeFieldErrors(w, http.StatusBadRequest, codeInvalidJSON, "request has fields of the wrong type",
+			map[string]string{typeErr.Field: "must be a " + typeErr.Type.String()})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields.
+		name := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeFieldErrors(w, http.StatusBadRequest, codeInvalidJSON, "request has unknown fields",
+			map[string]string{name: "is not a known field"})
+	case errors.Is(err, io.EOF):
+		writeError(w, http.StatusBadRequest, codeInvalidJSON, "body must not be empty")
+	default:
+		writeError(w, http.StatusBadRequest, codeInvalidJSON, "body is not valid JSON")
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FilePostRepository keeps posts in memory and writes them to a JSON file
+// after every change, so they survive restarts. A change that cannot be
+// written is rolled back and the error returned.
+type FilePostRepository struct {
+	path string
+	mu   sync.Mutex // serialises writes so the file matches memory
+	mem  *MemoryPostRepository
+}
+
+// postsFile is the on-disk layout. NextID is kept so that IDs of deleted
+// posts are not reused after a restart.
+type postsFile struct {
+	NextID int64  `json:"next_id"`
+	Posts  []Post `json:"posts"`
+}
+
+// NewFilePostRepository loads the posts stored at path. A missing file is
+// treated as an empty repository and created on the first write.
+func NewFilePostRepository(path string) (*FilePostRepository, error) {
+	r := &FilePostRepository{path: path, mem: NewMemoryPostRepository()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored postsFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	for _, p := range stored.Posts {
+		r.mem.put(p)
+	}
+	if stored.NextID > r.mem.nextID {
+		r.mem.nextID = stored.NextID
+	}
+	return r, nil
+}
+
+func (r *FilePostRepository) List(offset, limit int) ([]Post, int, error) {
+	return r.mem.List(offset, limit)
+}
+
+func (r *FilePostRepository) Get(id int64) (Post, error) {
+	return r.mem.Get(id)
+}
+
+func (r *FilePostRepository) Create(post Post) (Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	created, err := r.mem.Create(post)
+	if err != nil {
+		return Post{}, err
+	}
+	if err := r.save(); err != nil {
+		r.mem.Delete(created.ID)
+		return Post{}, err
+	}
+	return created, nil
+}
+
+func (r *FilePostRepository) Update(id int64, post Post) (Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, err := r.mem.Get(id)
+	if err != nil {
+		return Post{}, err
+	}
+	updated, err := r.mem.Update(id, post)
+	if err != nil {
+		return Post{}, err
+	}
+	if err := r.save(); err != nil {
+		r.mem.put(old)
+		return Post{}, err
+	}
+	return updated, nil
+}
+
+func (r *FilePostRepository) Delete(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, err := r.mem.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := r.mem.Delete(id); err != nil {
+		return err
+	}
+	if err := r.save(); err != nil {
+		r.mem.put(old)
+		return err
+	}
+	return nil
+}
+
+// save writes all posts to a temporary file and renames it over the real
+// one, so a crash mid-write never leaves a truncated file behind.
+func (r *FilePostRepository) save() error {
+	r.mem.mu.RLock()
+	stored := postsFile{NextID: r.mem.nextID, Posts: make([]Post, 0, len(r.mem.posts))}
+	for _, p := range r.mem.posts {
+		stored.Posts = append(stored.Posts, p)
+	}
+	r.mem.mu.RUnlock()
+	sort.Slice(stored.Posts, func(i, j int) bool { return stored.Posts[i].ID < stored.Posts[j].ID })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
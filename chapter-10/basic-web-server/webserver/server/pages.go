@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// pages holds one template per page, each combined with the shared layout.
+var pages = parsePages("home.html", "about.html", "posts.html")
+
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("2 Jan 2006 15:04") },
+}
+
+func parsePages(names ...string) map[string]*template.Template {
+	pages := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		pages[name] = template.Must(template.New("layout.html").Funcs(templateFuncs).
+			ParseFS(templateFiles, "templates/layout.html", "templates/"+name))
+	}
+	return pages
+}
+
+// layoutData is passed to the layout; Data is handed on to the page's
+// "content" template.
+type layoutData struct {
+	Title string
+	Data  any
+}
+
+// render executes a page into a buffer first, so a template error becomes a
+// 500 instead of a half-written page.
+func render(w http.ResponseWriter, status int, name, title string, data any) {
+	var buf bytes.Buffer
+	if err := pages[name].Execute(&buf, layoutData{Title: title, Data: data}); err != nil {
+		http.Error(w, "could not render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+func HomeHandler(w http.ResponseWriter, r *http.Request) {
+	render(w, http.StatusOK, "home.html", "Home", nil)
+}
+
+func AboutHandler(w http.ResponseWriter, r *http.Request) {
+	render(w, http.StatusOK, "about.html", "About", nil)
+}
+
+// postsPageData is the data of the posts listing page. Prev and Next are
+// zero when there is no such page.
+type postsPageData struct {
+	Posts      []Post
+	Page       int
+	Pages      int
+	Prev, Next int
+}
+
+func (s *Server) postsPage(w http.ResponseWriter, r *http.Request) {
+	page, offset, err := parsePage(r, defaultPerPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	posts, total, err := s.posts.List(offset, defaultPerPage)
+	if err != nil {
+		http.Error(w, "could not list posts", http.StatusInternalServerError)
+		return
+	}
+
+	data := postsPageData{Posts: posts, Page: page, Pages: (total + defaultPerPage - 1) / defaultPerPage}
+	if data.Pages == 0 {
+		data.Pages = 1
+	}
+	if page > 1 {
+		data.Prev = page - 1
+	}
+	if page < data.Pages {
+		data.Next = page + 1
+	}
+	render(w, http.StatusOK, "posts.html", "Posts", data)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostsPage(t *testing.T) {
+	repo := NewMemoryPostRepository()
+	repo.Create(Post{Message: "<b>hi</b>", Author: "ann"})
+	for i := 0; i < defaultPerPage; i++ {
+		repo.Create(Post{Message: fmt.Sprintf("post %d", i), Author: "bob"})
+	}
+	srv := New(repo)
+
+	first := do(t, srv, http.MethodGet, "/blog", "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, first.Code)
+	}
+	body := first.Body.String()
+	if !strings.Contains(body, "&lt;b&gt;hi&lt;/b&gt;") {
+		t.Errorf("expected the message to be escaped, got %q", body)
+	}
+	if !strings.Contains(body, "Page 1 of 2") || !strings.Contains(body, `href="/blog?page=2"`) {
+		t.Errorf("expected a link to page 2, got %q", body)
+	}
+
+	second := do(t, srv, http.MethodGet, "/blog?page=2", "").Body.String()
+	if !strings.Contains(second, "post 19") || strings.Contains(second, "?page=3") {
+		t.Errorf("unexpected second page %q", second)
+	}
+
+	empty := do(t, New(NewMemoryPostRepository()), http.MethodGet, "/blog", "").Body.String()
+	if !strings.Contains(empty, "No posts yet.") || !strings.Contains(empty, "Page 1 of 1") {
+		t.Errorf("unexpected empty page %q", empty)
+	}
+
+	for _, page := range []string{"0", "9223372036854775807"} {
+		if code := do(t, srv, http.MethodGet, "/blog?page="+page, "").Code; code != http.StatusBadRequest {
+			t.Errorf("expected %d for page %s, got %d", http.StatusBadRequest, page, code)
+		}
+	}
+}
+
+func TestStaticFiles(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+
+	recorder := do(t, srv, http.MethodGet, "/static/style.css", "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("expected a CSS content type, got %q", ct)
+	}
+	if cc := recorder.Header().Get("Cache-Control"); cc != staticMaxAge {
+		t.Errorf("expected Cache-Control %q, got %q", staticMaxAge, cc)
+	}
+	etag := recorder.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/style.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	revalidated := httptest.NewRecorder()
+	srv.ServeHTTP(revalidated, req)
+	if revalidated.Code != http.StatusNotModified || revalidated.Body.Len() != 0 {
+		t.Errorf("expected %d with an empty body, got %d", http.StatusNotModified, revalidated.Code)
+	}
+
+	for _, target := range []string{"/static/missing.css", "/static/"} {
+		if code := do(t, srv, http.MethodGet, target, "").Code; code != http.StatusNotFound {
+			t.Errorf("%s: expected not found, got %d", target, code)
+		}
+	}
+}
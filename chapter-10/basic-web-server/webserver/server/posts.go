@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// postInput is the body accepted by POST and PUT; IDs and timestamps are
+// assigned by the server.
+type postInput struct {
+	Message string `json:"message" validate:"required,max=280"`
+	Author  string `json:"author" validate:"required,max=50"`
+}
+
+// postList is the body of GET /posts.
+type postList struct {
+	Posts   []Post `json:"posts"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int    `json:"total"`
+}
+
+func (s *Server) listPosts(w http.ResponseWriter, r *http.Request) {
+	perPage, err := queryInt(r, "per_page", defaultPerPage)
+	if err != nil || perPage < 1 || perPage > maxPerPage {
+		writeError(w, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("per_page must be between 1 and %d", maxPerPage))
+		return
+	}
+	page, offset, err := parsePage(r, perPage)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	posts, total, err := s.posts.List(offset, perPage)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, "could not list posts")
+		return
+	}
+	writeJSON(w, http.StatusOK, postList{Posts: posts, Page: page, PerPage: perPage, Total: total})
+}
+
+func (s *Server) getPost(w http.ResponseWriter, r *http.Request) {
+	id, ok := postID(w, r)
+	if !ok {
+		return
+	}
+	post, err := s.posts.Get(id)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+func (s *Server) createPost(w http.ResponseWriter, r *http.Request) {
+	var input postInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	post, err := s.posts.Create(Post{Message: input.Message, Author: input.Author})
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/posts/%d", post.ID))
+	writeJSON(w, http.StatusCreated, post)
+}
+
+func (s *Server) updatePost(w http.ResponseWriter, r *http.Request) {
+	id, ok := postID(w, r)
+	if !ok {
+		return
+	}
+	var input postInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+	post, err := s.posts.Update(id, Post{Message: input.Message, Author: input.Author})
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+func (s *Server) deletePost(w http.ResponseWriter, r *http.Request) {
+	id, ok := postID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.posts.Delete(id); err != nil {
+		writeRepoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// postID parses the {id} path segment, answering 400 if it is not a number.
+func postID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		writeError(w, http.StatusBadRequest, codeBadRequest, "invalid post id")
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePage reads the page query parameter, 1 if absent, and returns it
+// with the offset of its first post. Pages whose offset would not fit in an
+// int are rejected.
+func parsePage(r *http.Request, perPage int) (page, offset int, err error) {
+	page, err = queryInt(r, "page", 1)
+	if err != nil || page < 1 {
+		return 0, 0, errors.New("page must be a positive integer")
+	}
+	if last := math.MaxInt / perPage; page > last {
+		return 0, 0, fmt.Errorf("page must be at most %d", last)
+	}
+	return page, (page - 1) * perPage, nil
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
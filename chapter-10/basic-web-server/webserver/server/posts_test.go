@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func do(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func decode[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(recorder.Body.Bytes(), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", recorder.Body.String(), err)
+	}
+	return v
+}
+
+func TestPostsCRUD(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+
+	created := do(t, srv, http.MethodPost, "/posts", `{"message": "Hello", "author": "ann"}`)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("create: expected %d, got %d", http.StatusCreated, created.Code)
+	}
+	if ct := created.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("create: expected JSON content type, got %q", ct)
+	}
+	post := decode[Post](t, created)
+	if post.ID != 1 || post.Message != "Hello" || post.Author != "ann" || post.CreatedAt.IsZero() {
+		t.Errorf("create: unexpected post %+v", post)
+	}
+
+	got := do(t, srv, http.MethodGet, "/posts/1", "")
+	if got.Code != http.StatusOK || decode[Post](t, got) != post {
+		t.Errorf("get: expected %+v, got %d %q", post, got.Code, got.Body.String())
+	}
+
+	updated := do(t, srv, http.MethodPut, "/posts/1", `{"message": "Hello again", "author": "ann"}`)
+	if updated.Code != http.StatusOK {
+		t.Fatalf("update: expected %d, got %d", http.StatusOK, updated.Code)
+	}
+	after := decode[Post](t, updated)
+	if after.Message != "Hello again" || !after.CreatedAt.Equal(post.CreatedAt) || after.UpdatedAt.Before(post.UpdatedAt) {
+		t.Errorf("update: unexpected post %+v", after)
+	}
+
+	deleted := do(t, srv, http.MethodDelete, "/posts/1", "")
+	if deleted.Code != http.StatusNoContent || deleted.Body.Len() != 0 {
+		t.Errorf("delete: expected %d with empty body, got %d %q", http.StatusNoContent, deleted.Code, deleted.Body.String())
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		recorder := do(t, srv, method, "/posts/1", `{"message": "x", "author": "y"}`)
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s after delete: expected %d, got %d", method, http.StatusNotFound, recorder.Code)
+		}
+		if e := decode[errorResponse](t, recorder).Error; e.Code != codeNotFound || e.Message != "post not found" {
+			t.Errorf("%s after delete: unexpected body %v", method, e)
+		}
+	}
+
+	// IDs are not reused after a delete.
+	again := decode[Post](t, do(t, srv, http.MethodPost, "/posts", `{"message": "Next", "author": "bob"}`))
+	if again.ID != 2 {
+		t.Errorf("expected ID 2, got %d", again.ID)
+	}
+}
+
+func TestPostsBadRequests(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+	tests := []struct {
+		method, target, body string
+		status               int
+		code                 string
+		fields               map[string]string
+	}{
+		{http.MethodPost, "/posts", `{"message": `, http.StatusBadRequest, codeInvalidJSON, nil},
+		{http.MethodPut, "/posts/1", `not json`, http.StatusBadRequest, codeInvalidJSON, nil},
+		{http.MethodPost, "/posts", `[]`, http.StatusBadRequest, codeInvalidJSON, nil},
+		{http.MethodPost, "/posts", `{"message": "a", "author": "b"} {}`, http.StatusBadRequest, codeInvalidJSON, nil},
+		{http.MethodPost, "/posts", `{"message": 1, "author": "b"}`, http.StatusBadRequest, codeInvalidJSON,
+			map[string]string{"message": "must be a string"}},
+		{http.MethodPost, "/posts", `{"message": "a", "author": "b", "id": 7}`, http.StatusBadRequest, codeInvalidJSON,
+			map[string]string{"id": "is not a known field"}},
+		{http.MethodPost, "/posts", `{"message": "  ", "author": "` + strings.Repeat("x", 51) + `"}`,
+			http.StatusUnprocessableEntity, codeValidation,
+			map[string]string{"message": "is required", "author": "must be at most 50 characters"}},
+		{http.MethodPut, "/posts/1", `{"author": "b"}`, http.StatusUnprocessableEntity, codeValidation,
+			map[string]string{"message": "is required"}},
+		{http.MethodPost, "/posts", `{"message": "` + strings.Repeat("x", maxBodyBytes) + `", "author": "b"}`,
+			http.StatusRequestEntityTooLarge, codeBodyTooLarge, nil},
+		{http.MethodGet, "/posts/abc", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodGet, "/posts/0", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodGet, "/posts?page=0", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodGet, "/posts?page=x", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodGet, "/posts?page=9223372036854775807", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodGet, "/posts?per_page=1000", "", http.StatusBadRequest, codeBadRequest, nil},
+		{http.MethodPatch, "/posts/1", "", http.StatusMethodNotAllowed, codeMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		recorder := do(t, srv, tt.method, tt.target, tt.body)
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.target, tt.status, recorder.Code)
+			continue
+		}
+		e := decode[errorResponse](t, recorder).Error
+		if e.Code != tt.code || e.Message == "" || !maps.Equal(e.Fields, tt.fields) {
+			t.Errorf("%s %s: unexpected error %+v", tt.method, tt.target, e)
+		}
+	}
+}
+
+func TestPostsPagination(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+	for i := 0; i < 5; i++ {
+		do(t, srv, http.MethodPost, "/posts", `{"message": "m", "author": "a"}`)
+	}
+
+	tests := []struct {
+		target   string
+		page     int
+		perPage  int
+		firstID  int64
+		received int
+	}{
+		{"/posts", 1, defaultPerPage, 1, 5},
+		{"/posts?per_page=2", 1, 2, 1, 2},
+		{"/posts?page=2&per_page=2", 2, 2, 3, 2},
+		{"/posts?page=3&per_page=2", 3, 2, 5, 1},
+		{"/posts?page=4&per_page=2", 4, 2, 0, 0},
+	}
+	for _, tt := range tests {
+		list := decode[postList](t, do(t, srv, http.MethodGet, tt.target, ""))
+		if list.Page != tt.page || list.PerPage != tt.perPage || list.Total != 5 || len(list.Posts) != tt.received {
+			t.Errorf("%s: unexpected page %+v", tt.target, list)
+			continue
+		}
+		if tt.received > 0 && list.Posts[0].ID != tt.firstID {
+			t.Errorf("%s: expected first ID %d, got %d", tt.target, tt.firstID, list.Posts[0].ID)
+		}
+	}
+
+	// An empty page is an empty array, not null.
+	recorder := do(t, srv, http.MethodGet, "/posts?page=9", "")
+	if !strings.Contains(recorder.Body.String(), `"posts":[]`) {
+		t.Errorf("expected an empty posts array, got %q", recorder.Body.String())
+	}
+}
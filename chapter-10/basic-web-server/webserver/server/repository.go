@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrPostNotFound is returned by repositories when no post has the given ID.
+var ErrPostNotFound = errors.New("post not found")
+
+type Post struct {
+	ID        int64     `json:"id"`
+	Message   string    `json:"message"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PostRepository stores posts. Implementations assign IDs and timestamps and
+// must be safe for concurrent use.
+type PostRepository interface {
+	// List returns up to limit posts ordered by ID, skipping the first
+	// offset, together with the total number of posts.
+	List(offset, limit int) ([]Post, int, error)
+	Get(id int64) (Post, error)
+	Create(post Post) (Post, error)
+	// Update replaces the message and author of a post.
+	Update(id int64, post Post) (Post, error)
+	Delete(id int64) error
+}
+
+// MemoryPostRepository keeps posts in memory; they are lost on restart.
+type MemoryPostRepository struct {
+	mu     sync.RWMutex
+	posts  map[int64]Post
+	nextID int64
+	now    func() time.Time
+}
+
+func NewMemoryPostRepository() *MemoryPostRepository {
+	return &MemoryPostRepository{
+		posts:  make(map[int64]Post),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+func (r *MemoryPostRepository) List(offset, limit int) ([]Post, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]Post, 0, len(r.posts))
+	for _, p := range r.posts {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	total := len(all)
+	offset = min(max(offset, 0), total)
+	end := total
+	if limit >= 0 && limit < total-offset {
+		end = offset + limit
+	}
+	return all[offset:end], total, nil
+}
+
+func (r *MemoryPostRepository) Get(id int64) (Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.posts[id]
+	if !ok {
+		return Post{}, ErrPostNotFound
+	}
+	return p, nil
+}
+
+func (r *MemoryPostRepository) Create(post Post) (Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now().UTC()
+	post.ID = r.nextID
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	r.nextID++
+	r.posts[post.ID] = post
+	return post, nil
+}
+
+func (r *MemoryPostRepository) Update(id int64, post Post) (Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.posts[id]
+	if !ok {
+		return Post{}, ErrPostNotFound
+	}
+	existing.Message = post.Message
+	existing.Author = post.Author
+	existing.UpdatedAt = r.now().UTC()
+	r.posts[id] = existing
+	return existing, nil
+}
+
+func (r *MemoryPostRepository) Delete(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[id]; !ok {
+		return ErrPostNotFound
+	}
+	delete(r.posts, id)
+	return nil
+}
+
+// put stores post as is, keeping its ID and timestamps. It is used to roll
+// back changes and to load saved posts.
+func (r *MemoryPostRepository) put(post Post) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posts[post.ID] = post
+	if post.ID >= r.nextID {
+		r.nextID = post.ID + 1
+	}
+}
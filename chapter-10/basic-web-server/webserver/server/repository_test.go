@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testRepository(t *testing.T, repo PostRepository) {
+	t.Helper()
+	a, _ := repo.Create(Post{Message: "a", Author: "ann"})
+	b, _ := repo.Create(Post{Message: "b", Author: "bob"})
+	if a.ID != 1 || b.ID != 2 {
+		t.Fatalf("unexpected IDs %d, %d", a.ID, b.ID)
+	}
+
+	if _, err := repo.Update(b.ID, Post{Message: "b2", Author: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(a.ID); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := repo.Update(a.ID, Post{}); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected ErrPostNotFound, got %v", err)
+	}
+
+	posts, total, err := repo.List(0, 10)
+	if err != nil || total != 1 || len(posts) != 1 || posts[0].Message != "b2" {
+		t.Errorf("unexpected list %v, %d, %v", posts, total, err)
+	}
+	// Out-of-range offsets and limits are clamped.
+	for _, window := range [][2]int{{-5, 10}, {0, math.MaxInt}, {math.MaxInt, 10}, {math.MinInt, math.MaxInt}} {
+		posts, total, err := repo.List(window[0], window[1])
+		if err != nil || total != 1 || (window[0] < 1) != (len(posts) == 1) {
+			t.Errorf("List(%d, %d): unexpected list %v, %d, %v", window[0], window[1], posts, total, err)
+		}
+	}
+}
+
+func TestMemoryPostRepository(t *testing.T) {
+	testRepository(t, NewMemoryPostRepository())
+}
+
+func TestFilePostRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.json")
+	repo, err := NewFilePostRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRepository(t, repo)
+
+	// A new repository on the same file sees the same posts and does not
+	// reuse the deleted ID.
+	reopened, err := NewFilePostRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := reopened.Get(2)
+	if err != nil || post.Message != "b2" {
+		t.Errorf("expected post 2 after reopening, got %+v, %v", post, err)
+	}
+	created, _ := reopened.Create(Post{Message: "c", Author: "cy"})
+	if created.ID != 3 {
+		t.Errorf("expected ID 3 after reopening, got %d", created.ID)
+	}
+}
+
+func TestFilePostRepositoryRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFilePostRepository(filepath.Join(dir, "missing", "posts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(Post{Message: "a"}); err == nil {
+		t.Fatal("expected an error writing into a missing directory")
+	}
+	if _, total, _ := repo.List(0, 10); total != 0 {
+		t.Errorf("expected the failed create to be rolled back, got %d posts", total)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte("{"), 0644)
+	if _, err := NewFilePostRepository(bad); err == nil {
+		t.Error("expected an error for a corrupt file")
+	}
+}
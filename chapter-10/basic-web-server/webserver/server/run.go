@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ListenAndServe listens on cfg.Addr and serves h until ctx is cancelled; see
+// Serve.
+func ListenAndServe(ctx context.Context, cfg Config, h http.Handler) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, ln, cfg, h)
+}
+
+// Serve accepts connections on ln until ctx is cancelled. It then stops
+// accepting new connections and waits up to cfg.ShutdownTimeout for
+// in-flight requests to finish before closing the rest. It returns nil after
+// a clean shutdown.
+func Serve(ctx context.Context, ln net.Listener, cfg Config, h http.Handler) error {
+	if err := cfg.Validate(); err != nil {
+		ln.Close()
+		return err
+	}
+	srv := &http.Server{
+		Handler:           h,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if cfg.TLS() {
+			errc <- srv.ServeTLS(ln, cfg.CertFile, cfg.KeyFile)
+		} else {
+			errc <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errc:
+		// The server stopped on its own, e.g. because of a bad certificate.
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		// Requests still running after the deadline are cut off.
+		srv.Close()
+	}
+	if serveErr := <-errc; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- Serve(ctx, ln, DefaultConfig(), h) }()
+
+	url := "http://" + ln.Addr().String() + "/"
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+
+	// Shutdown waits for the in-flight request instead of returning.
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned %v before the in-flight request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("expected new connections to be refused during shutdown")
+	}
+
+	close(release)
+	if got := <-body; got != "done" {
+		t.Errorf("expected the in-flight request to complete, got %q", got)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	cfg := DefaultConfig()
+	cfg.ShutdownTimeout = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- Serve(ctx, ln, cfg, h) }()
+
+	go http.Get("http://" + ln.Addr().String() + "/")
+	<-started
+	cancel()
+	if err := <-served; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the shutdown deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("WEBSERVER_ADDR", ":9090")
+	t.Setenv("WEBSERVER_WRITE_TIMEOUT", "3s")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":9090" || cfg.WriteTimeout != 3*time.Second || cfg.ReadTimeout != DefaultConfig().ReadTimeout {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	t.Setenv("WEBSERVER_IDLE_TIMEOUT", "soon")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CertFile = "cert.pem"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a certificate without a key")
+	}
+	cfg.KeyFile = "key.pem"
+	if err := cfg.Validate(); err != nil || !cfg.TLS() {
+		t.Errorf("expected a valid TLS config, got %v", err)
+	}
+	cfg.IdleTimeout = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for a negative timeout")
+	}
+}
@@ -1,44 +1,54 @@
 package server
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 )
 
-type Post struct {
-	Message string `json:"message"`
-	Author  string `json:"author"`
+// Server routes requests to the page, static file and posts handlers.
+type Server struct {
+	mux    *http.ServeMux
+	posts  PostRepository
+	static http.Handler
+	pages  map[string]bool // patterns of the HTML pages
 }
 
-func HomeHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprint(w, "Welcome to the Home page!")
+// New returns a server that stores posts in posts.
+func New(posts PostRepository) *Server {
+	s := &Server{mux: http.NewServeMux(), posts: posts, static: mustStaticHandler(), pages: make(map[string]bool)}
+	s.routes()
+	return s
 }
-func AboutHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprint(w, "This is the about page!")
+
+func (s *Server) routes() {
+	s.page("/{$}", HomeHandler)
+	s.page("/about", AboutHandler)
+	s.page("GET /blog", s.postsPage)
+	s.page("/blog", pageMethodNotAllowed("GET, HEAD"))
+	s.mux.Handle("GET /static/{path...}", s.static)
+	s.mux.HandleFunc("GET /posts", s.listPosts)
+	s.mux.HandleFunc("POST /posts", s.createPost)
+	s.mux.HandleFunc("GET /posts/{id}", s.getPost)
+	s.mux.HandleFunc("PUT /posts/{id}", s.updatePost)
+	s.mux.HandleFunc("DELETE /posts/{id}", s.deletePost)
+	s.mux.HandleFunc("/posts", methodNotAllowed("GET, HEAD, POST"))
+	s.mux.HandleFunc("/posts/{id}", methodNotAllowed("GET, HEAD, PUT, DELETE"))
+	s.mux.HandleFunc("/static/{path...}", methodNotAllowed("GET, HEAD"))
+	s.mux.HandleFunc("/", notFound)
+}
+
+// page registers the handler of an HTML page, whose errors are answered in
+// plain text rather than as JSON.
+func (s *Server) page(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+	s.pages[pattern] = true
+}
+
+// isPage reports whether r is routed to an HTML page.
+func (s *Server) isPage(r *http.Request) bool {
+	_, pattern := s.mux.Handler(r)
+	return s.pages[pattern]
 }
 
-func PostsHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("Query parameters:", r.URL.Query())
-	switch r.Method {
-	case http.MethodGet:
-		fmt.Fprint(w, "This is a list of posts!")
-	case http.MethodPost:
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Error reading the body", http.StatusBadRequest)
-			return // Important to return here!
-		}
-		var post Post
-		err = json.Unmarshal(body, &post)
-		if err != nil {
-			http.Error(w, "Error unmarshalling json", http.StatusBadRequest)
-			return
-		}
-		fmt.Fprintf(w, "Post created:\n")
-		fmt.Fprintf(w, "Message: %s\nAuthor: %s\n", post.Message, post.Author)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
 }
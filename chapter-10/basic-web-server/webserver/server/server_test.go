@@ -23,8 +23,11 @@ func TestHomeHandler(t *testing.T) {
 	expected := "Welcome to the Home page!"
 	actual := recorder.Body.String()
 
-	if actual != expected {
-		t.Errorf("HomeHandler failed, expected %q, got %q", expected, actual)
+	if !strings.Contains(actual, expected) || !strings.Contains(actual, "<nav>") {
+		t.Errorf("HomeHandler failed, expected a page with %q, got %q", expected, actual)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("HomeHandler failed, expected HTML, got %q", ct)
 	}
 }
 
@@ -44,53 +47,104 @@ func TestAboutHandler(t *testing.T) {
 	expected := "This is the about page!"
 	actual := recorder.Body.String()
 
-	if actual != expected {
-		t.Errorf("AboutHandler failed, expected %q, got %q", expected, actual)
+	if !strings.Contains(actual, expected) || !strings.Contains(actual, "<nav>") {
+		t.Errorf("AboutHandler failed, expected a page with %q, got %q", expected, actual)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("AboutHandler failed, expected HTML, got %q", ct)
 	}
 }
 
 func TestPostsHandler(t *testing.T) {
-	t.Run("Test GET request", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodGet, "/posts", nil)
+	srv := New(NewMemoryPostRepository())
+
+	t.Run("Test POST request", func(t *testing.T) {
+		body := `{"message": "Test message", "author": "test"}`
+		req, err := http.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
 		if err != nil {
 			t.Fatalf("Error creating request: %v", err)
 		}
 
+		req.Header.Set("Content-Type", "application/json")
+
 		recorder := httptest.NewRecorder()
-		PostsHandler(recorder, req)
+		srv.ServeHTTP(recorder, req)
 
-		if recorder.Code != http.StatusOK {
-			t.Fatalf("PostsHandler failed, expected %d, got %d", http.StatusOK, recorder.Code)
+		if recorder.Code != http.StatusCreated {
+			t.Fatalf("PostsHandler failed, expected %d, got %d", http.StatusCreated, recorder.Code)
 		}
-
-		expected := "This is a list of posts!"
-		actual := recorder.Body.String()
-		if actual != expected {
-			t.Errorf("PostsHandler GET failed, expected %q, got %q", expected, actual)
+		if location := recorder.Header().Get("Location"); location != "/posts/1" {
+			t.Errorf("PostsHandler POST failed, expected Location %q, got %q", "/posts/1", location)
 		}
 	})
 
-	t.Run("Test POST request", func(t *testing.T) {
-		body := `{"message": "Test message", "author": "test"}`
-		req, err := http.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+	t.Run("Test GET request", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/posts", nil)
 		if err != nil {
 			t.Fatalf("Error creating request: %v", err)
 		}
 
-		req.Header.Set("Content-Type", "application/json")
-
 		recorder := httptest.NewRecorder()
-		PostsHandler(recorder, req)
+		srv.ServeHTTP(recorder, req)
 
 		if recorder.Code != http.StatusOK {
 			t.Fatalf("PostsHandler failed, expected %d, got %d", http.StatusOK, recorder.Code)
 		}
+		if !strings.Contains(recorder.Body.String(), `"message":"Test message"`) {
+			t.Errorf("PostsHandler GET failed, got %q", recorder.Body.String())
+		}
+	})
+
+	t.Run("Test unsupported method", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/posts", nil)
+		if err != nil {
+			t.Fatalf("Error creating request: %v", err)
+		}
 
-		actual := recorder.Body.String()
+		recorder := httptest.NewRecorder()
+		srv.ServeHTTP(recorder, req)
 
-		expected := "Post created:\nMessage: Test message\nAuthor: test\n"
-		if actual != expected {
-			t.Errorf("PostsHandler POST failed, expected %q, got %q", expected, actual)
+		if recorder.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("PostsHandler failed, expected %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
 		}
 	})
 }
+
+func TestUnknownPath(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+	for _, target := range []string{"/nowhere", "/posts/1/comments", "/static/missing.css"} {
+		recorder := do(t, srv, http.MethodGet, target, "")
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: expected %d, got %d", target, http.StatusNotFound, recorder.Code)
+			continue
+		}
+		if e := decode[errorResponse](t, recorder).Error; e.Code != codeNotFound {
+			t.Errorf("%s: unexpected error %+v", target, e)
+		}
+	}
+
+	// Wrong methods on GET-only paths are 405, not 404; pages answer in
+	// plain text.
+	if recorder := do(t, srv, http.MethodPost, "/static/style.css", ""); recorder.Code != http.StatusMethodNotAllowed ||
+		decode[errorResponse](t, recorder).Error.Code != codeMethodNotAllowed {
+		t.Errorf("POST /static: expected a JSON %d, got %d %q", http.StatusMethodNotAllowed, recorder.Code, recorder.Body.String())
+	}
+	recorder := do(t, srv, http.MethodPost, "/blog", "")
+	if recorder.Code != http.StatusMethodNotAllowed || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("POST /blog: expected a plain-text %d, got %d %v", http.StatusMethodNotAllowed, recorder.Code, recorder.Header())
+	}
+}
+
+func TestInternalError(t *testing.T) {
+	srv := New(NewMemoryPostRepository())
+	recorder := httptest.NewRecorder()
+	srv.InternalError(recorder, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	if recorder.Code != http.StatusInternalServerError || decode[errorResponse](t, recorder).Error.Code != codeInternal {
+		t.Errorf("/posts: expected a JSON %d, got %d %q", http.StatusInternalServerError, recorder.Code, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	srv.InternalError(recorder, httptest.NewRequest(http.MethodGet, "/blog?page=2", nil))
+	if recorder.Code != http.StatusInternalServerError || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("/blog: expected a plain-text %d, got %d %v", http.StatusInternalServerError, recorder.Code, recorder.Header())
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"time"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// staticMaxAge is how long browsers may cache static assets before
+// revalidating them with their ETag.
+const staticMaxAge = "public, max-age=3600"
+
+// staticHandler serves embedded assets. Embedded files never change while
+// the server runs, so each file's ETag is a content hash computed once.
+type staticHandler struct {
+	files fs.FS
+	etags map[string]string
+}
+
+func newStaticHandler(files fs.FS) (*staticHandler, error) {
+	h := &staticHandler{files: files, etags: make(map[string]string)}
+	err := fs.WalkDir(files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(files, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		h.etags[path] = `"` + hex.EncodeToString(sum[:8]) + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ServeHTTP serves the file named by the {path...} wildcard. Directories are
+// not listed. Conditional requests are answered by http.ServeContent.
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	etag, ok := h.etags[name]
+	if !ok {
+		notFound(w, r)
+		return
+	}
+	f, err := h.files.Open(name)
+	if err != nil {
+		notFound(w, r)
+		return
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "file is not seekable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", staticMaxAge)
+	http.ServeContent(w, r, name, time.Time{}, content)
+}
+
+func mustStaticHandler() *staticHandler {
+	files, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	h, err := newStaticHandler(files)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validate checks the string fields of the struct v points to against their
+// `validate` tags and returns a message per failing field, keyed by the
+// field's JSON name. Rules are separated by commas:
+//
+//	required  the value must not be blank
+//	min=N     the value must have at least N characters
+//	max=N     the value must have at most N characters
+//
+// An unknown rule is a programming error and panics.
+func validate(v any) map[string]string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+
+	fields := make(map[string]string)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		rules := field.Tag.Get("validate")
+		if rules == "" || field.Type.Kind() != reflect.String {
+			continue
+		}
+		if msg := checkString(rv.Field(i).String(), rules); msg != "" {
+			fields[jsonName(field)] = msg
+		}
+	}
+	return fields
+}
+
+// checkString returns the message for the first rule value breaks, or "".
+func checkString(value, rules string) string {
+	length := utf8.RuneCountInString(value)
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if strings.TrimSpace(value) == "" {
+				return "is required"
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				panic(fmt.Sprintf("validate: bad %s rule %q", name, rule))
+			}
+			if name == "min" && length < n {
+				return fmt.Sprintf("must be at least %d characters", n)
+			}
+			if name == "max" && length > n {
+				return fmt.Sprintf("must be at most %d characters", n)
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q", rule))
+		}
+	}
+	return ""
+}
+
+// jsonName returns the name field has in JSON.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -5,72 +5,264 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"strings"
+	"time"
 )
 
+// DefaultQueueSize is the number of outgoing lines buffered per client.
+const DefaultQueueSize = 64
+
+// Server is a chat server with IRC-style channels. Its clients and rooms are
+// owned by the ManageConnections goroutine; other goroutines talk to it over
+// channels.
+type Server struct {
+	// QueueSize is how many lines may wait to be written to a client before
+	// it is considered too slow and disconnected. Set it before starting.
+	QueueSize int
+	// MaxLineLength is the longest line, in bytes, a client may send; a
+	// longer one disconnects it.
+	MaxLineLength int
+	// IdleTimeout disconnects clients that send nothing for this long. Zero
+	// means no timeout.
+	IdleTimeout time.Duration
+	// MessageRate and MessageBurst limit how fast a client may send lines.
+	// Lines over the limit are dropped with a warning, and a client that
+	// keeps going is disconnected. A MessageRate of zero means no limit.
+	MessageRate  float64
+	MessageBurst int
+	// HistorySize is how many recent lines each channel keeps and replays
+	// to newcomers. Set it before starting.
+	HistorySize int
+
+	clients  map[net.Conn]*Client
+	rooms    map[string]*Room
+	join     chan joinRequest
+	messages chan Message
+	quit     chan leaveRequest
+	now      func() time.Time
+
+	history    map[string]*history // by roomKey
+	historyLog *os.File
+}
+
+// NewServer returns a server with only DefaultRoom. Start it with
+// ManageConnections.
+func NewServer() *Server {
+	return &Server{
+		QueueSize:     DefaultQueueSize,
+		MaxLineLength: DefaultMaxLineLength,
+		IdleTimeout:   DefaultIdleTimeout,
+		MessageRate:   DefaultMessageRate,
+		MessageBurst:  DefaultMessageBurst,
+		HistorySize:   DefaultHistorySize,
+		clients:       make(map[net.Conn]*Client),
+		rooms:         map[string]*Room{roomKey(DefaultRoom): newRoom(DefaultRoom)},
+		join:          make(chan joinRequest),
+		messages:      make(chan Message),
+		quit:          make(chan leaveRequest),
+		now:           time.Now,
+		history:       make(map[string]*history),
+	}
+}
+
+// Client is a connected user, talking in one room at a time. Lines for it
+// are queued on out and written by its own goroutine, so a slow reader never
+// blocks the server.
 type Client struct {
-	conn   net.Conn
-	writer *bufio.Writer
+	conn    net.Conn
+	nick    string
+	room    *Room
+	out     chan string
+	limiter *rateLimiter
+}
+
+// Message is a line typed by the client on Sender.
+type Message struct {
+	Sender net.Conn
+	Text   string
+}
+
+// joinRequest asks ManageConnections to admit a client under a nickname.
+// The reason for a refusal is sent back on reply.
+type joinRequest struct {
+	client *Client
+	nick   string
+	reply  chan error
 }
 
-var clients = make(map[net.Conn]Client)
-var clientChannel = make(chan Client)
-var messageChannel = make(chan string)
-var quitChannel = make(chan net.Conn)
+// leaveRequest tells ManageConnections that a client is gone. A non-empty
+// reason is sent to the client before it is disconnected.
+type leaveRequest struct {
+	conn   net.Conn
+	reason string
+}
 
-func HandleConnection(conn net.Conn) {
-	client := Client{
-		conn:   conn,
-		writer: bufio.NewWriter(conn),
+// HandleConnection asks the client for a nickname until it picks a free one,
+// then forwards every line it sends to ManageConnections. Lines longer than
+// MaxLineLength and silences longer than IdleTimeout end the connection.
+func (s *Server) HandleConnection(conn net.Conn) {
+	client := &Client{
+		conn:    conn,
+		out:     make(chan string, s.QueueSize),
+		limiter: newRateLimiter(s.MessageRate, s.MessageBurst, s.now()),
 	}
-	clientChannel <- client
+	go client.writeLoop()
+
 	reader := bufio.NewReader(conn)
 	for {
-		message, err := reader.ReadString('\n')
+		client.send("*** Enter your nickname:")
+		line, err := s.readLine(conn, reader)
 		if err != nil {
-			quitChannel <- conn
+			// Never joined, so nothing else sends to the client.
+			if reason := disconnectReason(err); reason != "" {
+				client.send("*** Disconnected: " + reason)
+			}
+			close(client.out)
 			return
 		}
-		messageChannel <- message
+		reply := make(chan error)
+		s.join <- joinRequest{client: client, nick: strings.TrimSpace(line), reply: reply}
+		err = <-reply
+		if err == nil {
+			break
+		}
+		client.send("*** " + err.Error())
+	}
+
+	for {
+		message, err := s.readLine(conn, reader)
+		if err != nil {
+			s.quit <- leaveRequest{conn: conn, reason: disconnectReason(err)}
+			return
+		}
+		s.messages <- Message{Sender: conn, Text: message}
 	}
 }
 
-func BroadcastMessage(message string, sender net.Conn) {
-	for conn, client := range clients {
-		if conn != sender {
-			_, err := client.writer.WriteString(message)
-			if err != nil {
-				log.Println("Error sending message:", err)
-				quitChannel <- conn
-			}
-			err = client.writer.Flush() // flush the messages immediately.
-			if err != nil {
-				log.Println("Error flushing the messages:", err)
-				quitChannel <- conn
-			}
+// readLine reads the next line from conn, waiting at most IdleTimeout.
+func (s *Server) readLine(conn net.Conn, reader *bufio.Reader) (string, error) {
+	if s.IdleTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+	}
+	return readLine(reader, s.MaxLineLength)
+}
+
+// send queues one line for the client. A client whose queue is full is
+// disconnected; its HandleConnection then reports the quit.
+func (c *Client) send(line string) {
+	select {
+	case c.out <- line:
+	default:
+		log.Println("Client too slow, disconnecting:", c.conn.RemoteAddr())
+		c.conn.Close()
+	}
+}
+
+// sendLines queues lines as a single entry, so that a reply of any length
+// takes one place in the queue.
+func (c *Client) sendLines(lines []string) {
+	c.send(strings.Join(lines, "\n"))
+}
+
+// writeLoop writes queued lines until out is closed, then closes the
+// connection. Lines queued after a write error are discarded.
+func (c *Client) writeLoop() {
+	writer := bufio.NewWriter(c.conn)
+	var err error
+	for line := range c.out {
+		if err != nil {
+			continue
+		}
+		_, err = writer.WriteString(line + "\n")
+		if err == nil && len(c.out) == 0 {
+			err = writer.Flush() // flush once the queue is drained
+		}
+		if err != nil {
+			log.Println("Error sending message:", err)
+			c.conn.Close()
 		}
 	}
+	c.conn.Close()
 }
 
-func ManageConnections() {
+// ManageConnections owns the client list: it admits clients, runs commands,
+// delivers messages and removes clients that leave. It runs forever.
+func (s *Server) ManageConnections() {
 	for {
 		select {
-		case client := <-clientChannel:
-			clients[client.conn] = client
-			fmt.Println("New client connected: ", client.conn.RemoteAddr())
+		case req := <-s.join:
+			if err := s.checkNick(req.nick, nil); err != nil {
+				req.reply <- err
+				continue
+			}
+			req.client.nick = req.nick
+			s.clients[req.client.conn] = req.client
+			req.reply <- nil
+			fmt.Println("New client connected: ", req.client.conn.RemoteAddr(), req.nick)
+			req.client.send(fmt.Sprintf("*** Welcome, %s! Type /help for a list of commands.", req.nick))
+			s.enterRoom(req.client, DefaultRoom)
 
-		case message := <-messageChannel:
-			if len(clients) == 0 {
-				continue // skip if no clients are available
+		case message := <-s.messages:
+			client, ok := s.clients[message.Sender]
+			if !ok {
+				continue // the client has just quit
 			}
-			// to test functionality we will use the first element of the client map
-			for conn := range clients {
-				BroadcastMessage(message, conn)
-				break
+			if !client.limiter.allow(s.now()) {
+				if client.limiter.strikes >= maxStrikes {
+					s.removeClient(client.conn, "flooding")
+				} else {
+					client.send("*** Slow down! Your message was dropped.")
+				}
+				continue
 			}
-		case conn := <-quitChannel:
-			fmt.Println("Client disconnected: ", conn.RemoteAddr())
-			delete(clients, conn)
-			conn.Close()
+			s.handleMessage(client, message.Text)
+
+		case req := <-s.quit:
+			s.removeClient(req.conn, req.reason)
+		}
+	}
+}
+
+// handleMessage runs a command or sends a chat line to everyone else in the
+// sender's room, prefixed with the sender's nickname.
+func (s *Server) handleMessage(from *Client, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if strings.HasPrefix(text, "/") {
+		s.runCommand(from, text)
+		return
+	}
+	line := fmt.Sprintf("[%s] %s", from.nick, text)
+	from.room.broadcast(line, from.conn)
+	s.record(from.room, line)
+}
+
+// removeClient forgets the client on conn, telling it reason if there is
+// one. Its writer closes the connection once the lines already queued, such
+// as a goodbye, are written.
+func (s *Server) removeClient(conn net.Conn, reason string) {
+	client, ok := s.clients[conn]
+	if !ok {
+		return // already removed, e.g. after /quit
+	}
+	fmt.Println("Client disconnected: ", conn.RemoteAddr(), client.nick, reason)
+	if reason != "" {
+		client.send("*** Disconnected: " + reason)
+	}
+	delete(s.clients, conn)
+	close(client.out)
+	s.leaveRoom(client, fmt.Sprintf("*** %s has quit", client.nick))
+}
+
+// findClient returns the client using nick, ignoring case.
+func (s *Server) findClient(nick string) *Client {
+	for _, client := range s.clients {
+		if strings.EqualFold(client.nick, nick) {
+			return client
 		}
 	}
+	return nil
 }
@@ -2,67 +2,211 @@ package chat
 
 import (
 	"bufio"
+	"fmt"
 	"net"
-	"sync"
+	"strings"
 	"testing"
 	"time"
 )
 
-func TestBroadcastMessage(t *testing.T) {
-	// Simulate two clients
-	listener, err := net.Listen("tcp", "127.0.0.1:0") // selecting random port
+// newServer starts a chat server whose clients connect over net.Pipe.
+func newServer() *Server {
+	s := NewServer()
+	go s.ManageConnections()
+	return s
+}
+
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dial connects to s without choosing a nickname yet.
+func dial(t *testing.T, s *Server) *testClient {
+	t.Helper()
+	serverEnd, clientEnd := net.Pipe()
+	go s.HandleConnection(serverEnd)
+	t.Cleanup(func() { clientEnd.Close() })
+	c := &testClient{t: t, conn: clientEnd, reader: bufio.NewReader(clientEnd)}
+	c.expect("*** Enter your nickname:")
+	return c
+}
+
+// join connects to s and picks nick.
+func join(t *testing.T, s *Server, nick string) *testClient {
+	t.Helper()
+	c := dial(t, s)
+	c.say(nick)
+	c.expect("*** Welcome, " + nick + "! Type /help for a list of commands.")
+	c.expect("*** Now talking in " + DefaultRoom)
+	return c
+}
+
+func (c *testClient) say(line string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		c.t.Fatalf("Error sending %q: %v", line, err)
+	}
+}
+
+func (c *testClient) read() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := c.reader.ReadString('\n')
 	if err != nil {
-		t.Fatalf("Error creating listener: %v", err)
+		c.t.Fatalf("Error receiving message: %v", err)
 	}
-	defer listener.Close()
-
-	// Create 2 clients to simulate chat
-	var conns []net.Conn
-	var wg sync.WaitGroup
-	wg.Add(2)
-	for i := 0; i < 2; i++ {
-		go func() {
-			defer wg.Done()
-			conn, err := net.Dial("tcp", listener.Addr().String())
-			if err != nil {
-				t.Fatalf("Error connecting to server: %v", err)
-			}
-			conns = append(conns, conn)
-		}()
+	return strings.TrimSuffix(line, "\n")
+}
+
+func (c *testClient) expect(want string) {
+	c.t.Helper()
+	if got := c.read(); got != want {
+		c.t.Fatalf("expected %q, got %q", want, got)
 	}
+}
 
-	wg.Wait() // wait for all connections to be established
+func TestBroadcastMessage(t *testing.T) {
+	s := newServer()
+	alice := join(t, s, "alice")
+	bob := join(t, s, "bob")
+	alice.expect("*** bob has joined #general")
 
-	for _, conn := range conns {
-		go HandleConnection(conn)
-	}
+	alice.say("Test message from client 1")
+	bob.expect("[alice] Test message from client 1")
 
-	go ManageConnections()
+	bob.say("hi alice")
+	alice.expect("[bob] hi alice")
 
-	// Send a message from the first client
-	message := "Test message from client 1\n"
+	bob.say("/quit")
+	bob.expect("*** Goodbye!")
+	alice.expect("*** bob has quit")
+	alice.say("/quit")
+	alice.expect("*** Goodbye!")
+}
 
-	client := Client{conn: conns[0], writer: bufio.NewWriter(conns[0])}
-	clients[conns[0]] = client // manually adding the client as we did not receive it in the client channel
+func TestNicknames(t *testing.T) {
+	s := newServer()
+	carol := join(t, s, "carol")
 
-	messageChannel <- message
+	taken := dial(t, s)
+	taken.say("CAROL")
+	taken.expect("*** the nickname CAROL is already taken")
+	taken.expect("*** Enter your nickname:")
+	taken.say("bad nick")
+	taken.expect("*** nicknames may only contain letters, digits, '_' and '-'")
+	taken.expect("*** Enter your nickname:")
+	taken.say("dave")
+	taken.expect("*** Welcome, dave! Type /help for a list of commands.")
+	taken.expect("*** Now talking in #general")
+	carol.expect("*** dave has joined #general")
 
-	// Give the server some time to broadcast the message
-	time.Sleep(time.Second)
+	taken.say("/nick david")
+	taken.expect("*** dave is now known as david")
+	carol.expect("*** dave is now known as david")
 
-	// Read the message received by the other client
-	reader := bufio.NewReader(conns[1])
-	receivedMessage, err := reader.ReadString('\n')
+	carol.say("/who")
+	carol.expect("*** 2 users in #general: carol, david")
 
-	if err != nil {
-		t.Fatalf("Error receiving message: %v", err)
+	carol.say("/nick david")
+	carol.expect("*** the nickname david is already taken")
+
+	taken.say("/quit")
+	taken.expect("*** Goodbye!")
+	carol.expect("*** david has quit")
+	carol.say("/quit")
+	carol.expect("*** Goodbye!")
+}
+
+func TestPrivateMessages(t *testing.T) {
+	s := newServer()
+	erin := join(t, s, "erin")
+	frank := join(t, s, "frank")
+	erin.expect("*** frank has joined #general")
+	grace := join(t, s, "grace")
+	erin.expect("*** grace has joined #general")
+	frank.expect("*** grace has joined #general")
+
+	erin.say("/msg Frank   psst, over here")
+	frank.expect("*erin* psst, over here")
+	erin.expect("-> *frank* psst, over here")
+
+	erin.say("/msg nobody hello")
+	erin.expect("*** No such user: nobody")
+	erin.say("/msg frank")
+	erin.expect("*** Usage: /msg <user> <text>")
+	erin.say("/shout hi")
+	erin.expect("*** Unknown command /shout, type /help for a list of commands")
+
+	// grace saw none of it: her next line is the public one.
+	frank.say("public")
+	grace.expect("[frank] public")
+
+	for _, c := range []*testClient{erin, frank, grace} {
+		c.say("/quit")
 	}
-	if receivedMessage != message {
-		t.Fatalf("Incorrect message received by client, expected %q got %q", message, receivedMessage)
+}
+
+func TestFanOut(t *testing.T) {
+	s := newServer()
+	nicks := []string{"u0", "u1", "u2", "u3"}
+	var clients []*testClient
+	for i, nick := range nicks {
+		c := join(t, s, nick)
+		for _, earlier := range clients[:i] {
+			earlier.expect("*** " + nick + " has joined #general")
+		}
+		clients = append(clients, c)
 	}
 
-	//close the connections.
-	for _, conn := range conns {
-		conn.Close()
+	for i, sender := range clients {
+		line := fmt.Sprintf("hello from %d", i)
+		sender.say(line)
+		for j, c := range clients {
+			if j != i {
+				c.expect(fmt.Sprintf("[%s] %s", nicks[i], line))
+			}
+		}
+	}
+
+	// Nobody got their own line back: the next thing each sees is /who.
+	for _, c := range clients {
+		c.say("/who")
+		c.expect("*** 4 users in #general: u0, u1, u2, u3")
+	}
+}
+
+func TestSlowClientIsDisconnected(t *testing.T) {
+	s := NewServer()
+	s.QueueSize = 4
+	s.MessageRate = 0
+	go s.ManageConnections()
+
+	join(t, s, "slow") // never reads again
+	fast := join(t, s, "fast")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			fast.say(fmt.Sprintf("line %d", i))
+		}
+		fast.say("/who")
+	}()
+
+	for {
+		line := fast.read()
+		if line == "*** 1 users in #general: fast" {
+			break
+		}
+		if line != "*** slow has quit" {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the fast client was blocked by the slow one")
 	}
 }
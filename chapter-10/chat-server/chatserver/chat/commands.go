@@ -0,0 +1,188 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxNickLength = 16
+
+// command is a slash command. args is the text after the command name.
+type command struct {
+	usage string
+	help  string
+	run   func(s *Server, c *Client, args string)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"nick":    {"/nick <name>", "change your nickname", (*Server).nickCommand},
+		"msg":     {"/msg <user> <text>", "send a private message", (*Server).msgCommand},
+		"who":     {"/who", "list the users in your channel", (*Server).whoCommand},
+		"join":    {"/join <#channel>", "switch to a channel, creating it if needed", (*Server).joinCommand},
+		"part":    {"/part", "leave your channel for " + DefaultRoom, (*Server).partCommand},
+		"list":    {"/list", "list channels with their member counts", (*Server).listCommand},
+		"history": {"/history [n]", "show the last n lines of your channel", (*Server).historyCommand},
+		"topic":   {"/topic [text]", "show or set the topic of your channel", (*Server).topicCommand},
+		"quit":    {"/quit", "leave the chat", (*Server).quitCommand},
+		"help":    {"/help", "show this list", (*Server).helpCommand},
+	}
+}
+
+// runCommand runs a line starting with "/".
+func (s *Server) runCommand(c *Client, line string) {
+	name, args, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+	cmd, ok := commands[strings.ToLower(name)]
+	if !ok {
+		c.send(fmt.Sprintf("*** Unknown command /%s, type /help for a list of commands", name))
+		return
+	}
+	cmd.run(s, c, strings.TrimSpace(args))
+}
+
+// checkNick reports why nick cannot be used by self (nil for a new client).
+func (s *Server) checkNick(nick string, self *Client) error {
+	if nick == "" || len(nick) > maxNickLength {
+		return fmt.Errorf("nicknames must be 1 to %d characters long", maxNickLength)
+	}
+	for _, r := range nick {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return errors.New("nicknames may only contain letters, digits, '_' and '-'")
+		}
+	}
+	if other := s.findClient(nick); other != nil && other != self {
+		return fmt.Errorf("the nickname %s is already taken", nick)
+	}
+	return nil
+}
+
+func (s *Server) nickCommand(c *Client, args string) {
+	if err := s.checkNick(args, c); err != nil {
+		c.send("*** " + err.Error())
+		return
+	}
+	old := c.nick
+	c.nick = args
+	c.room.broadcast(fmt.Sprintf("*** %s is now known as %s", old, args), nil)
+}
+
+func (s *Server) msgCommand(c *Client, args string) {
+	nick, text, _ := strings.Cut(args, " ")
+	text = strings.TrimSpace(text)
+	if nick == "" || text == "" {
+		c.send("*** Usage: " + commands["msg"].usage)
+		return
+	}
+	to := s.findClient(nick)
+	if to == nil {
+		c.send(fmt.Sprintf("*** No such user: %s", nick))
+		return
+	}
+	to.send(fmt.Sprintf("*%s* %s", c.nick, text))
+	if to != c {
+		c.send(fmt.Sprintf("-> *%s* %s", to.nick, text))
+	}
+}
+
+func (s *Server) whoCommand(c *Client, args string) {
+	nicks := make([]string, 0, len(c.room.members))
+	for _, client := range c.room.members {
+		nicks = append(nicks, client.nick)
+	}
+	sort.Strings(nicks)
+	c.send(fmt.Sprintf("*** %d users in %s: %s", len(nicks), c.room.name, strings.Join(nicks, ", ")))
+}
+
+func (s *Server) joinCommand(c *Client, args string) {
+	if err := checkRoomName(args); err != nil {
+		c.send("*** " + err.Error())
+		return
+	}
+	if roomKey(args) == roomKey(c.room.name) {
+		c.send(fmt.Sprintf("*** You are already in %s", c.room.name))
+		return
+	}
+	s.leaveRoom(c, fmt.Sprintf("*** %s has left %s", c.nick, c.room.name))
+	s.enterRoom(c, args)
+}
+
+func (s *Server) partCommand(c *Client, args string) {
+	if roomKey(c.room.name) == roomKey(DefaultRoom) {
+		c.send(fmt.Sprintf("*** You cannot leave %s", DefaultRoom))
+		return
+	}
+	s.leaveRoom(c, fmt.Sprintf("*** %s has left %s", c.nick, c.room.name))
+	s.enterRoom(c, DefaultRoom)
+}
+
+func (s *Server) listCommand(c *Client, args string) {
+	keys := make([]string, 0, len(s.rooms))
+	for key := range s.rooms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := []string{fmt.Sprintf("*** %d channels:", len(keys))}
+	for _, key := range keys {
+		room := s.rooms[key]
+		line := fmt.Sprintf("*** %s (%d)", room.name, len(room.members))
+		if room.topic != "" {
+			line += " " + room.topic
+		}
+		lines = append(lines, line)
+	}
+	// One entry however many channels there are, so a long listing cannot
+	// fill the queue and disconnect the client.
+	c.sendLines(lines)
+}
+
+func (s *Server) topicCommand(c *Client, args string) {
+	room := c.room
+	if args == "" {
+		if room.topic == "" {
+			c.send(fmt.Sprintf("*** No topic is set for %s", room.name))
+		} else {
+			c.send(fmt.Sprintf("*** Topic for %s: %s", room.name, room.topic))
+		}
+		return
+	}
+	room.topic = args
+	room.broadcast(fmt.Sprintf("*** %s set the topic of %s to: %s", c.nick, room.name, args), nil)
+}
+
+func (s *Server) historyCommand(c *Client, args string) {
+	n := s.HistorySize
+	if args != "" {
+		var err error
+		n, err = strconv.Atoi(args)
+		if err != nil || n < 1 {
+			c.send("*** Usage: " + commands["history"].usage)
+			return
+		}
+	}
+	if len(s.history[roomKey(c.room.name)].last(n)) == 0 {
+		c.send(fmt.Sprintf("*** No messages in %s yet", c.room.name))
+		return
+	}
+	s.replay(c, n)
+}
+
+func (s *Server) quitCommand(c *Client, args string) {
+	c.send("*** Goodbye!")
+	s.removeClient(c.conn, "")
+}
+
+func (s *Server) helpCommand(c *Client, args string) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.send(fmt.Sprintf("*** %-20s %s", commands[name].usage, commands[name].help))
+	}
+}
@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHistorySize is how many recent lines are kept per channel.
+const DefaultHistorySize = 20
+
+// history is a ring buffer holding the most recent lines of a channel.
+type history struct {
+	lines []string
+	next  int // where the next line goes
+	full  bool
+}
+
+// newHistory returns a history of size lines; a negative size keeps none.
+func newHistory(size int) *history {
+	return &history{lines: make([]string, max(size, 0))}
+}
+
+func (h *history) add(line string) {
+	if len(h.lines) == 0 {
+		return
+	}
+	h.lines[h.next] = line
+	h.next = (h.next + 1) % len(h.lines)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// last returns up to n of the most recent lines, oldest first. A nil
+// history has none.
+func (h *history) last(n int) []string {
+	if h == nil {
+		return nil
+	}
+	count := h.next
+	if h.full {
+		count = len(h.lines)
+	}
+	if n > count {
+		n = count
+	}
+	out := make([]string, 0, n)
+	for i := n; i > 0; i-- {
+		out = append(out, h.lines[(h.next-i+len(h.lines))%len(h.lines)])
+	}
+	return out
+}
+
+// roomHistory returns the history of the channel called name, creating it
+// for the first line said there. Histories outlive their channels, so a
+// channel that is emptied and joined again keeps its past. Only channels
+// with something said in them get one, so joining channels does not use
+// memory that is never freed.
+func (s *Server) roomHistory(name string) *history {
+	h, ok := s.history[roomKey(name)]
+	if !ok {
+		h = newHistory(s.HistorySize)
+		s.history[roomKey(name)] = h
+	}
+	return h
+}
+
+// record adds a chat line to the history of room and to the history log,
+// if one is open.
+func (s *Server) record(room *Room, line string) {
+	s.roomHistory(room.name).add(line)
+	if s.historyLog == nil {
+		return
+	}
+	_, err := fmt.Fprintf(s.historyLog, "%s\t%s\t%s\n", s.now().UTC().Format(time.RFC3339), room.name, line)
+	if err != nil {
+		log.Println("Error writing history log:", err)
+	}
+}
+
+// replay sends the client up to n recent lines of its channel. They are
+// queued as one entry, so a history longer than QueueSize does not
+// disconnect the client.
+func (s *Server) replay(c *Client, n int) {
+	lines := s.history[roomKey(c.room.name)].last(n)
+	if len(lines) == 0 {
+		return
+	}
+	reply := make([]string, 0, len(lines)+2)
+	reply = append(reply, fmt.Sprintf("*** Last %d messages in %s:", len(lines), c.room.name))
+	reply = append(reply, lines...)
+	c.sendLines(append(reply, "*** End of history"))
+}
+
+// OpenHistoryLog loads the channel histories stored in the append-only log
+// at path, creating it if needed, and appends every later chat line to it.
+// Call it before ManageConnections. Each log line holds a timestamp, a
+// channel name and the chat line, separated by tabs; malformed lines, such
+// as one cut short by a crash, are skipped.
+func (s *Server) OpenHistoryLog(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) != 3 || checkRoomName(fields[1]) != nil {
+			continue
+		}
+		s.roomHistory(fields[1]).add(fields[2])
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return err
+	}
+	s.historyLog = f
+	return nil
+}
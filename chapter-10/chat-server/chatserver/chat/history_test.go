@@ -0,0 +1,150 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRing(t *testing.T) {
+	h := newHistory(3)
+	if got := h.last(5); len(got) != 0 {
+		t.Errorf("expected an empty history, got %v", got)
+	}
+	for _, line := range []string{"a", "b", "c", "d"} {
+		h.add(line)
+	}
+	if got := h.last(5); !reflect.DeepEqual(got, []string{"b", "c", "d"}) {
+		t.Errorf("expected the last three lines, got %v", got)
+	}
+	if got := h.last(2); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("expected the last two lines, got %v", got)
+	}
+	// A negative size keeps nothing rather than panicking.
+	h = newHistory(-1)
+	h.add("a")
+	if got := h.last(5); len(got) != 0 {
+		t.Errorf("expected an empty history, got %v", got)
+	}
+}
+
+func TestHistoryReplay(t *testing.T) {
+	s := NewServer()
+	s.HistorySize = 2
+	go s.ManageConnections()
+
+	alice := join(t, s, "alice")
+	for _, line := range []string{"one", "two", "three"} {
+		alice.say(line)
+	}
+	alice.say("/history 1")
+	alice.expect("*** Last 1 messages in #general:")
+	alice.expect("[alice] three")
+	alice.expect("*** End of history")
+
+	bob := dial(t, s)
+	bob.say("bob")
+	bob.expect("*** Welcome, bob! Type /help for a list of commands.")
+	bob.expect("*** Now talking in #general")
+	bob.expect("*** Last 2 messages in #general:")
+	bob.expect("[alice] two")
+	bob.expect("[alice] three")
+	bob.expect("*** End of history")
+
+	bob.say("/join #empty")
+	bob.expect("*** Now talking in #empty")
+	bob.say("/history")
+	bob.expect("*** No messages in #empty yet")
+	bob.say("/history none")
+	bob.expect("*** Usage: /history [n]")
+}
+
+func TestHistoryLongerThanQueue(t *testing.T) {
+	s := NewServer()
+	s.QueueSize = 4
+	s.HistorySize = 10
+	s.MessageRate = 0
+	go s.ManageConnections()
+
+	alice := join(t, s, "alice")
+	for i := range s.HistorySize {
+		alice.say(fmt.Sprint(i))
+	}
+	alice.say("/history 1")
+	alice.expect("*** Last 1 messages in #general:")
+	alice.expect("[alice] 9")
+	alice.expect("*** End of history")
+
+	bob := join(t, s, "bob")
+	bob.expect("*** Last 10 messages in #general:")
+	for i := range s.HistorySize {
+		bob.expect(fmt.Sprintf("[alice] %d", i))
+	}
+	bob.expect("*** End of history")
+	bob.say("/who")
+	if line := bob.read(); !strings.HasPrefix(line, "*** ") {
+		t.Errorf("expected bob to stay connected, got %q", line)
+	}
+}
+
+func TestHistoryOnlyForSpokenChannels(t *testing.T) {
+	s := newServer()
+	alice := join(t, s, "alice")
+	for _, name := range []string{"#r1", "#r2", "#r3"} {
+		alice.say("/join " + name)
+		alice.expect("*** Now talking in " + name)
+		alice.say("/history")
+		alice.expect("*** No messages in " + name + " yet")
+	}
+	alice.say("hello")
+	alice.say("/history")
+	alice.expect("*** Last 1 messages in #r3:")
+	alice.expect("[alice] hello")
+	alice.expect("*** End of history")
+
+	// The replies above were sent after the server handled the commands.
+	if len(s.history) != 1 || s.history["#r3"] == nil {
+		t.Errorf("expected a history for #r3 only, got %v", s.history)
+	}
+}
+
+func TestHistoryLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	os.WriteFile(path, []byte("2024-01-01T00:00:00Z\t#go\t[old] from before\ngarbage\n"), 0644)
+
+	s := NewServer()
+	if err := s.OpenHistoryLog(path); err != nil {
+		t.Fatal(err)
+	}
+	go s.ManageConnections()
+
+	alice := join(t, s, "alice")
+	alice.say("/join #Go")
+	alice.expect("*** Now talking in #Go")
+	alice.expect("*** Last 1 messages in #Go:")
+	alice.expect("[old] from before")
+	alice.expect("*** End of history")
+	alice.say("new line")
+	alice.say("/history")
+	alice.expect("*** Last 2 messages in #Go:")
+	alice.expect("[old] from before")
+	alice.expect("[alice] new line")
+	alice.expect("*** End of history")
+
+	// A restarted server sees both lines.
+	restarted := NewServer()
+	if err := restarted.OpenHistoryLog(path); err != nil {
+		t.Fatal(err)
+	}
+	got := restarted.roomHistory("#go").last(10)
+	if !reflect.DeepEqual(got, []string{"[old] from before", "[alice] new line"}) {
+		t.Errorf("unexpected history after restart %v", got)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasSuffix(string(data), "\t#Go\t[alice] new line\n") {
+		t.Errorf("unexpected log contents %q", data)
+	}
+}
@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"time"
+)
+
+// Defaults for the limits a Server puts on each client.
+const (
+	DefaultMaxLineLength = 1024
+	DefaultIdleTimeout   = 10 * time.Minute
+	DefaultMessageRate   = 5  // lines per second
+	DefaultMessageBurst  = 10 // lines sent at once before the rate applies
+)
+
+// maxStrikes is how many lines in a row a client may send over its rate
+// limit before it is disconnected.
+const maxStrikes = 3
+
+var errLineTooLong = errors.New("line too long")
+
+// readLine reads one line without its line ending. It fails with
+// errLineTooLong rather than buffer more than max bytes.
+func readLine(reader *bufio.Reader, max int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line)+len(chunk) > max+2 { // allow for "\r\n"
+			return "", errLineTooLong
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		line = line[:len(line)-1]
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+		if len(line) > max {
+			return "", errLineTooLong
+		}
+		return string(line), nil
+	}
+}
+
+// disconnectReason is the notice given to a client whose read failed, or ""
+// when the connection simply went away.
+func disconnectReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errLineTooLong):
+		return "line too long"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "idle timeout"
+	}
+	return ""
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at
+// rate tokens per second, and each line takes one.
+type rateLimiter struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	strikes int
+}
+
+func newRateLimiter(rate float64, burst int, now time.Time) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// allow reports whether a line sent at now is within the limit. A rate of
+// zero or less disables the limit.
+func (l *rateLimiter) allow(now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		l.strikes++
+		return false
+	}
+	l.tokens--
+	l.strikes = 0
+	return true
+}
@@ -0,0 +1,183 @@
+package chat
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadLine(t *testing.T) {
+	reader := bufio.NewReaderSize(strings.NewReader("short\r\nexactly10!\n"+strings.Repeat("x", 40)+"\nlast"), 16)
+	for _, want := range []string{"short", "exactly10!"} {
+		if got, err := readLine(reader, 10); got != want || err != nil {
+			t.Errorf("expected %q, got %q, %v", want, got, err)
+		}
+	}
+	if _, err := readLine(reader, 10); !errors.Is(err, errLineTooLong) {
+		t.Errorf("expected errLineTooLong, got %v", err)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, 3, start)
+	for i := 0; i < 3; i++ {
+		if !l.allow(start) {
+			t.Fatalf("line %d of the burst was refused", i+1)
+		}
+	}
+	if l.allow(start) || l.allow(start) || l.strikes != 2 {
+		t.Errorf("expected lines over the burst to be refused, got %d strikes", l.strikes)
+	}
+	if !l.allow(start.Add(500*time.Millisecond)) || l.strikes != 0 {
+		t.Error("expected a token after half a second at 2 per second")
+	}
+	if l.allow(start.Add(500 * time.Millisecond)) {
+		t.Error("expected only one new token")
+	}
+
+	unlimited := newRateLimiter(0, 0, start)
+	if !unlimited.allow(start) {
+		t.Error("expected a zero rate to allow everything")
+	}
+}
+
+// expectClosed reads until the server closes the connection, failing on
+// any line other than the ones expected.
+func (c *testClient) expectClosed(lines ...string) {
+	c.t.Helper()
+	for _, want := range lines {
+		c.expect(want)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if line, err := c.reader.ReadString('\n'); err != io.EOF {
+		c.t.Fatalf("expected the connection to be closed, got %q, %v", line, err)
+	}
+}
+
+func TestLongLineDisconnects(t *testing.T) {
+	s := NewServer()
+	s.MaxLineLength = 20
+	go s.ManageConnections()
+
+	early := dial(t, s)
+	early.say(strings.Repeat("n", 21))
+	early.expectClosed("*** Disconnected: line too long")
+
+	alice := join(t, s, "alice")
+	bob := join(t, s, "bob")
+	alice.expect("*** bob has joined #general")
+	bob.say(strings.Repeat("b", 20))
+	alice.expect("[bob] " + strings.Repeat("b", 20))
+	go bob.conn.Write([]byte(strings.Repeat("b", 1000) + "\n")) // may be cut off
+	bob.expectClosed("*** Disconnected: line too long")
+	alice.expect("*** bob has quit")
+}
+
+func TestIdleTimeout(t *testing.T) {
+	s := NewServer()
+	s.IdleTimeout = 50 * time.Millisecond
+	go s.ManageConnections()
+
+	alice := join(t, s, "alice")
+	alice.expectClosed("*** Disconnected: idle timeout")
+}
+
+func TestFloodingDisconnects(t *testing.T) {
+	s := NewServer()
+	s.MessageRate = 0.001
+	s.MessageBurst = 2
+	go s.ManageConnections()
+
+	alice := join(t, s, "alice")
+	bob := join(t, s, "bob")
+	alice.expect("*** bob has joined #general")
+
+	for i := 0; i < 2+maxStrikes; i++ {
+		bob.say("spam")
+	}
+	alice.expect("[bob] spam")
+	alice.expect("[bob] spam")
+	alice.expect("*** bob has quit")
+	bob.expectClosed(
+		"*** Slow down! Your message was dropped.",
+		"*** Slow down! Your message was dropped.",
+		"*** Disconnected: flooding",
+	)
+}
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 and returns
+// the certificate and key file paths.
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "chat test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestServeTLS(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	listener, err := Listen("127.0.0.1:0", certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	s := newServer()
+	go s.Serve(listener)
+
+	pool := x509.NewCertPool()
+	pemData, _ := os.ReadFile(certFile)
+	pool.AppendCertsFromPEM(pemData)
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: pool})
+	if err != nil {
+		t.Fatalf("TLS handshake failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+	c.expect("*** Enter your nickname:")
+	c.say("secure")
+	c.expect("*** Welcome, secure! Type /help for a list of commands.")
+
+	if _, err := Listen("127.0.0.1:0", certFile, ""); err == nil {
+		t.Error("expected an error for a certificate without a key")
+	}
+}
@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+)
+
+// Listen listens for TCP connections on addr. When certFile and keyFile are
+// both set the connections use TLS with that certificate.
+func Listen(addr, certFile, keyFile string) (net.Listener, error) {
+	if certFile == "" && keyFile == "" {
+		return net.Listen("tcp", addr)
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("TLS needs both a certificate and a key file")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return tls.Listen("tcp", addr, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+}
+
+// Serve accepts connections on listener and hands each to HandleConnection
+// until the listener is closed. ManageConnections must be running.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			log.Println("error accepting connection: ", err)
+			continue
+		}
+		go s.HandleConnection(conn)
+	}
+}
@@ -0,0 +1,86 @@
+package chat
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultRoom is the channel clients are put in when they connect or part.
+// It always exists.
+const DefaultRoom = "#general"
+
+const maxRoomLength = 32
+
+// Room is a channel. Chat lines are delivered only to its members.
+type Room struct {
+	name    string
+	topic   string
+	members map[net.Conn]*Client
+}
+
+func newRoom(name string) *Room {
+	return &Room{name: name, members: make(map[net.Conn]*Client)}
+}
+
+// broadcast queues message for every member but sender. A nil sender
+// reaches all members. It never blocks.
+func (r *Room) broadcast(message string, sender net.Conn) {
+	for conn, client := range r.members {
+		if conn != sender {
+			client.send(message)
+		}
+	}
+}
+
+// roomKey returns the key under which the room called name is stored, as
+// channel names are not case-sensitive.
+func roomKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// checkRoomName reports why name cannot be a channel name.
+func checkRoomName(name string) error {
+	if len(name) < 2 || len(name) > maxRoomLength || name[0] != '#' {
+		return fmt.Errorf("channel names start with '#' and are 2 to %d characters long", maxRoomLength)
+	}
+	for _, r := range name[1:] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Errorf("channel names may only contain letters, digits, '_' and '-' after the '#'")
+		}
+	}
+	return nil
+}
+
+// enterRoom makes name the client's current channel, creating it if needed,
+// and replays the channel's recent lines. The client must not be in a room.
+func (s *Server) enterRoom(c *Client, name string) {
+	room, ok := s.rooms[roomKey(name)]
+	if !ok {
+		room = newRoom(name)
+		s.rooms[roomKey(name)] = room
+	}
+	room.broadcast(fmt.Sprintf("*** %s has joined %s", c.nick, room.name), nil)
+	room.members[c.conn] = c
+	c.room = room
+	c.send(fmt.Sprintf("*** Now talking in %s", room.name))
+	if room.topic != "" {
+		c.send(fmt.Sprintf("*** Topic for %s: %s", room.name, room.topic))
+	}
+	s.replay(c, s.HistorySize)
+}
+
+// leaveRoom takes the client out of its current channel, telling the
+// remaining members why. Empty channels other than DefaultRoom are removed.
+func (s *Server) leaveRoom(c *Client, notice string) {
+	room := c.room
+	if room == nil {
+		return
+	}
+	delete(room.members, c.conn)
+	c.room = nil
+	room.broadcast(notice, nil)
+	if len(room.members) == 0 && roomKey(room.name) != roomKey(DefaultRoom) {
+		delete(s.rooms, roomKey(room.name))
+	}
+}
@@ -0,0 +1,98 @@
+package chat
+
+import "testing"
+
+func TestRooms(t *testing.T) {
+	s := NewServer()
+	s.HistorySize = 0 // replays are tested on their own
+	go s.ManageConnections()
+	alice := join(t, s, "alice")
+	bob := join(t, s, "bob")
+	alice.expect("*** bob has joined #general")
+	carol := join(t, s, "carol")
+	alice.expect("*** carol has joined #general")
+	bob.expect("*** carol has joined #general")
+
+	alice.say("/join #Go")
+	alice.expect("*** Now talking in #Go")
+	bob.expect("*** alice has left #general")
+	carol.expect("*** alice has left #general")
+
+	// Channel names ignore case.
+	bob.say("/join #go")
+	carol.expect("*** bob has left #general")
+	alice.expect("*** bob has joined #Go")
+	bob.expect("*** Now talking in #Go")
+
+	// Lines stay in their channel.
+	alice.say("hi gophers")
+	bob.expect("[alice] hi gophers")
+	carol.say("anyone here?")
+	carol.say("/who")
+	carol.expect("*** 1 users in #general: carol")
+
+	alice.say("/topic generics")
+	alice.expect("*** alice set the topic of #Go to: generics")
+	bob.expect("*** alice set the topic of #Go to: generics")
+
+	carol.say("/list")
+	carol.expect("*** 2 channels:")
+	carol.expect("*** #general (1)")
+	carol.expect("*** #Go (2) generics")
+
+	carol.say("/join #GO")
+	bob.expect("*** carol has joined #Go")
+	alice.expect("*** carol has joined #Go")
+	carol.expect("*** Now talking in #Go")
+	carol.expect("*** Topic for #Go: generics")
+
+	// Parting returns to the default room; the last one out removes the
+	// channel.
+	alice.say("/part")
+	alice.expect("*** Now talking in #general")
+	bob.expect("*** alice has left #Go")
+	carol.expect("*** alice has left #Go")
+	bob.say("/part")
+	bob.expect("*** Now talking in #general")
+	alice.expect("*** bob has joined #general")
+	carol.expect("*** bob has left #Go")
+	carol.say("/part")
+	carol.expect("*** Now talking in #general")
+	carol.say("/list")
+	carol.expect("*** 1 channels:")
+	carol.expect("*** #general (3)")
+
+	carol.say("/part")
+	carol.expect("*** You cannot leave #general")
+	carol.say("/join general")
+	carol.expect("*** channel names start with '#' and are 2 to 32 characters long")
+	carol.say("/join #general")
+	carol.expect("*** You are already in #general")
+	carol.say("/topic")
+	carol.expect("*** No topic is set for #general")
+}
+
+func TestListLongerThanQueue(t *testing.T) {
+	s := NewServer()
+	s.QueueSize = 2
+	go s.ManageConnections()
+
+	var names []string
+	for _, nick := range []string{"alice", "bob", "carol"} {
+		c := join(t, s, nick)
+		name := "#" + nick
+		c.say("/join " + name)
+		c.expect("*** Now talking in " + name)
+		names = append(names, name)
+	}
+
+	dave := join(t, s, "dave")
+	dave.say("/list")
+	dave.expect("*** 4 channels:")
+	for _, name := range names {
+		dave.expect("*** " + name + " (1)")
+	}
+	dave.expect("*** #general (1)")
+	dave.say("/list")
+	dave.expect("*** 4 channels:")
+}
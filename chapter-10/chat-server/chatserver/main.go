@@ -2,26 +2,46 @@ package main
 
 import (
 	"chatserver/chat"
+	"flag"
 	"fmt"
 	"log"
-	"net"
 )
 
 func main() {
-	listener, err := net.Listen("tcp", ":8080")
+	server := chat.NewServer()
+	addr := flag.String("addr", ":8080", "address to listen on")
+	certFile := flag.String("tls-cert", "", "TLS certificate file (enables TLS with -tls-key)")
+	keyFile := flag.String("tls-key", "", "TLS private key file")
+	flag.IntVar(&server.MaxLineLength, "max-line", server.MaxLineLength, "longest line in bytes a client may send")
+	flag.DurationVar(&server.IdleTimeout, "idle-timeout", server.IdleTimeout, "disconnect clients silent for this long (0 for never)")
+	flag.Float64Var(&server.MessageRate, "rate", server.MessageRate, "lines per second a client may send (0 for no limit)")
+	flag.IntVar(&server.MessageBurst, "burst", server.MessageBurst, "lines a client may send at once before -rate applies")
+	flag.IntVar(&server.HistorySize, "history", server.HistorySize, "recent lines kept per channel and replayed on join")
+	historyFile := flag.String("history-file", "", "append-only log that keeps channel history across restarts")
+	flag.Parse()
+	if server.HistorySize < 0 {
+		log.Fatal("Error: -history must not be negative")
+	}
+
+	if *historyFile != "" {
+		if err := server.OpenHistoryLog(*historyFile); err != nil {
+			log.Fatal("Error opening history log: ", err)
+		}
+	}
+
+	listener, err := chat.Listen(*addr, *certFile, *keyFile)
 	if err != nil {
 		log.Fatal("Error creating listener: ", err)
 	}
 	defer listener.Close()
 
-	fmt.Println("Server is listening at port 8080")
-	go chat.ManageConnections()
-	for {
-		conn, err := listener.Accept()
-		if err != nil {
-			log.Println("error accepting connection: ", err)
-			continue
-		}
-		go chat.HandleConnection(conn)
+	if *certFile != "" {
+		fmt.Println("Server is listening with TLS at", *addr)
+	} else {
+		fmt.Println("Server is listening at", *addr)
+	}
+	go server.ManageConnections()
+	if err := server.Serve(listener); err != nil {
+		log.Fatal(err)
 	}
 }
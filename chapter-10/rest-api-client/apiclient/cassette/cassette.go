@@ -0,0 +1,364 @@
+// Package cassette records HTTP interactions to fixture files ("cassettes")
+// and replays them, so tests of API clients run without the network and
+// always see the same responses.
+//
+// A Recorder is an http.RoundTripper. In tests, use it as the transport of
+// the client under test:
+//
+//	rec, err := cassette.New("testdata/todos.json", cassette.ModeReplay)
+//	...
+//	c, err := client.New(url, client.WithHTTPClient(&http.Client{Transport: rec}))
+//
+// To refresh a cassette, run once with ModeRecord against the real API and
+// call Save.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay answers only from the cassette; an unknown request fails
+	// with ErrNoInteraction.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request to the real transport and records it,
+	// replacing the cassette's contents when saved.
+	ModeRecord
+	// ModeReplayOrRecord replays known requests and records new ones.
+	ModeReplayOrRecord
+)
+
+// Redacted replaces the values of redacted headers and query parameters.
+const Redacted = "REDACTED"
+
+// DefaultRedactHeaders are the headers redacted unless Recorder.RedactHeaders
+// is set.
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// DefaultRedactQuery are the query parameters redacted unless
+// Recorder.RedactQuery is set.
+var DefaultRedactQuery = []string{"api_key", "apikey", "key", "token", "access_token", "client_secret"}
+
+// ErrNoInteraction is returned in replay mode for a request the cassette
+// does not hold.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+// Cassette is the file format: the interactions in the order they were
+// recorded.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is stored as text when it is valid UTF-8, so cassettes stay
+// readable and diffable, and as base64 otherwise.
+type Body []byte
+
+type encodedBody struct {
+	Base64 string `json:"base64"`
+}
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(encodedBody{Base64: base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+	var encoded encodedBody
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	*b = decoded
+	return err
+}
+
+// Matcher reports whether a recorded request answers req. Both URLs have
+// had RedactQuery applied.
+type Matcher func(req Request, recorded Request) bool
+
+// MatchMethodURLBody is the default Matcher: method, URL and body must be
+// equal.
+func MatchMethodURLBody(req Request, recorded Request) bool {
+	return req.Method == recorded.Method && req.URL == recorded.URL && bytes.Equal(req.Body, recorded.Body)
+}
+
+// Recorder is an http.RoundTripper that records and replays interactions.
+// It is safe for concurrent use.
+type Recorder struct {
+	// Transport sends requests that are recorded; http.DefaultTransport if
+	// nil.
+	Transport http.RoundTripper
+	// Match decides which recorded interaction answers a request;
+	// MatchMethodURLBody if nil.
+	Match Matcher
+	// RedactHeaders and RedactQuery name the request and response headers
+	// and URL query parameters whose values are never written to the
+	// cassette. They default to DefaultRedactHeaders and
+	// DefaultRedactQuery; set an empty, non-nil slice to redact nothing.
+	RedactHeaders []string
+	RedactQuery   []string
+
+	path string
+	mode Mode
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a recorder for the cassette at path, loading it unless mode
+// is ModeRecord. A missing cassette is an error in ModeReplay.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeReplayOrRecord {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("cassette: invalid file %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip answers req from the cassette or, when recording, from the real
+// transport.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := Request{Method: req.Method, URL: r.redactURL(req.URL), Body: body}
+
+	if r.mode != ModeRecord {
+		if resp, ok := r.replay(req, key); ok {
+			return resp, nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, key.URL)
+		}
+	}
+	// The body has been read, so the transport gets a copy of the request
+	// with a fresh one; req itself must not be modified.
+	send := req
+	if body != nil {
+		send = req.Clone(req.Context())
+		send.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return r.record(send, key)
+}
+
+// replay returns the response of the first unused matching interaction, so
+// repeated identical requests get the responses in recorded order. Once
+// all matches are used, the last one is reused.
+func (r *Recorder) replay(req *http.Request, key Request) (*http.Response, bool) {
+	match := r.Match
+	if match == nil {
+		match = MatchMethodURLBody
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := -1
+	for i, in := range r.cassette.Interactions {
+		if !match(key, in.Request) {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, false
+	}
+	r.used[found] = true
+	return r.cassette.Interactions[found].Response.toHTTP(req), true
+}
+
+func (r *Recorder) record(req *http.Request, key Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Header = r.redactHeader(req.Header)
+	in := Interaction{
+		Request:  key,
+		Response: Response{StatusCode: resp.StatusCode, Header: r.redactHeader(resp.Header), Body: respBody},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// Save writes the cassette to its file, creating directories as needed.
+// It does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Interactions returns a copy of the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// RedactAuth also redacts the header or query parameter that auth sends
+// its credential in, if it has a HeaderName method like the Authenticator
+// returned by client.APIKey or a QueryParam method like the one returned
+// by client.APIKeyQuery. Call it before the first request.
+func (r *Recorder) RedactAuth(auth any) {
+	if a, ok := auth.(interface{ HeaderName() string }); ok {
+		if r.RedactHeaders == nil {
+			r.RedactHeaders = slices.Clone(DefaultRedactHeaders)
+		}
+		r.RedactHeaders = append(r.RedactHeaders, a.HeaderName())
+	}
+	if a, ok := auth.(interface{ QueryParam() string }); ok {
+		if r.RedactQuery == nil {
+			r.RedactQuery = slices.Clone(DefaultRedactQuery)
+		}
+		r.RedactQuery = append(r.RedactQuery, a.QueryParam())
+	}
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	names := r.RedactHeaders
+	if names == nil {
+		names = DefaultRedactHeaders
+	}
+	out := h.Clone()
+	for _, name := range names {
+		if values := out.Values(name); len(values) > 0 {
+			out[http.CanonicalHeaderKey(name)] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	names := r.RedactQuery
+	if names == nil {
+		names = DefaultRedactQuery
+	}
+	if len(names) == 0 || u.RawQuery == "" {
+		return u.String()
+	}
+	redacted := *u
+	q := redacted.Query()
+	for key := range q {
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				q.Set(key, Redacted)
+			}
+		}
+	}
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
+
+// readBody reads and closes the request body, as a RoundTripper must.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	return body, err
+}
+
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
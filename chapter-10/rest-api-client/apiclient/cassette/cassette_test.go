@@ -0,0 +1,218 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, hc *http.Client, method, url, body string) (int, string, error) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Set-Cookie", "session=abc")
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(append([]byte("created "), body...))
+			return
+		}
+		io.WriteString(w, "call "+strings.Repeat("!", calls))
+	}))
+	path := filepath.Join(t.TempDir(), "fixtures", "api.json")
+
+	rec, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.RedactQuery = []string{"api_key"}
+	hc := &http.Client{Transport: rec}
+	get(t, hc, http.MethodGet, srv.URL+"/items?api_key=k1", "")
+	get(t, hc, http.MethodGet, srv.URL+"/items?api_key=k1", "")
+	get(t, hc, http.MethodPost, srv.URL+"/items", "a")
+	get(t, hc, http.MethodPost, srv.URL+"/items", "b")
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, _ := os.ReadFile(path)
+	for _, secret := range []string{"secret-token", "session=abc", "k1"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains the secret %q:\n%s", secret, data)
+		}
+	}
+
+	// Replaying needs no server. The query secret may differ, since it is
+	// redacted before matching.
+	replay, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.RedactQuery = []string{"api_key"}
+	hc = &http.Client{Transport: replay}
+	tests := []struct {
+		method, url, body string
+		status            int
+		want              string
+	}{
+		{http.MethodGet, srv.URL + "/items?api_key=other", "", http.StatusOK, "call !"},
+		{http.MethodGet, srv.URL + "/items?api_key=other", "", http.StatusOK, "call !!"},
+		{http.MethodGet, srv.URL + "/items?api_key=other", "", http.StatusOK, "call !!"}, // the last match is reused
+		{http.MethodPost, srv.URL + "/items", "b", http.StatusCreated, "created b"},
+		{http.MethodPost, srv.URL + "/items", "a", http.StatusCreated, "created a"},
+	}
+	for _, tt := range tests {
+		status, body, err := get(t, hc, tt.method, tt.url, tt.body)
+		if err != nil || status != tt.status || body != tt.want {
+			t.Errorf("%s %s %q: expected %d %q, got %d %q %v", tt.method, tt.url, tt.body, tt.status, tt.want, status, body, err)
+		}
+	}
+
+	if _, _, err := get(t, hc, http.MethodPost, srv.URL+"/items", "c"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction for an unknown body, got %v", err)
+	}
+}
+
+// queryAuth stands in for client.APIKeyQuery.
+type queryAuth string
+
+func (a queryAuth) QueryParam() string { return string(a) }
+
+func TestDefaultRedactQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	record := func(rec *Recorder) string {
+		t.Helper()
+		get(t, &http.Client{Transport: rec}, http.MethodGet, srv.URL+"/items?Token=t1&access_token=t2&sig=t3&page=2", "")
+		if err := rec.Save(); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(rec.path)
+		return string(data)
+	}
+
+	rec, _ := New(filepath.Join(t.TempDir(), "default.json"), ModeRecord)
+	data := record(rec)
+	if strings.Contains(data, "t1") || strings.Contains(data, "t2") || !strings.Contains(data, "sig=t3") || !strings.Contains(data, "page=2") {
+		t.Errorf("expected only the default parameters to be redacted:\n%s", data)
+	}
+
+	rec, _ = New(filepath.Join(t.TempDir(), "auth.json"), ModeRecord)
+	rec.RedactAuth(queryAuth("sig"))
+	rec.RedactAuth(func() {}) // not a query authenticator
+	if data := record(rec); strings.Contains(data, "t1") || strings.Contains(data, "t3") {
+		t.Errorf("expected the authenticator's parameter to be redacted too:\n%s", data)
+	}
+
+	rec, _ = New(filepath.Join(t.TempDir(), "none.json"), ModeRecord)
+	rec.RedactQuery = []string{}
+	if data := record(rec); !strings.Contains(data, "t1") {
+		t.Errorf("expected no query redaction when opted out:\n%s", data)
+	}
+}
+
+// headerAuth stands in for client.APIKey.
+type headerAuth string
+
+func (a headerAuth) HeaderName() string { return string(a) }
+
+func TestRedactAuthHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	rec, _ := New(filepath.Join(t.TempDir(), "header.json"), ModeRecord)
+	rec.RedactAuth(headerAuth("X-Secret"))
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/items", strings.NewReader("a"))
+	req.Header.Set("X-Secret", "s1")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	body := req.Body
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.Body != body {
+		t.Error("expected RoundTrip to leave the request's body alone")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(rec.path)
+	if strings.Contains(string(data), "s1") || strings.Contains(string(data), "secret-token") {
+		t.Errorf("expected the authenticator's header and the defaults to be redacted:\n%s", data)
+	}
+}
+
+func TestReplayOrRecord(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte{0xff, 0x00, 'x'}) // not UTF-8
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "api.json")
+
+	for round := 0; round < 2; round++ {
+		rec, err := New(path, ModeReplayOrRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, body, err := get(t, &http.Client{Transport: rec}, http.MethodGet, srv.URL+"/bin", "")
+		if err != nil || body != "\xff\x00x" {
+			t.Errorf("round %d: unexpected body %q, %v", round, body, err)
+		}
+		if err := rec.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the second round to replay, got %d calls", calls)
+	}
+
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+		t.Error("expected an error for a missing cassette in replay mode")
+	}
+}
+
+func TestCustomMatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.json")
+	os.WriteFile(path, []byte(`{"interactions": [{
+		"request": {"method": "GET", "url": "https://api.example.com/items?ts=1"},
+		"response": {"status_code": 200, "body": "ok"}
+	}]}`), 0644)
+
+	rec, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Match = func(req, recorded Request) bool {
+		return req.Method == recorded.Method && strings.Split(req.URL, "?")[0] == strings.Split(recorded.URL, "?")[0]
+	}
+	_, body, err := get(t, &http.Client{Transport: rec}, http.MethodGet, "https://api.example.com/items?ts=99", "")
+	if err != nil || body != "ok" {
+		t.Errorf("expected the custom matcher to ignore the query, got %q, %v", body, err)
+	}
+}
@@ -0,0 +1,269 @@
+// Package cli implements apiclient, a small curl-like command for JSON
+// APIs built on the client package.
+package cli
+
+import (
+	"apiclient/client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0
+	ExitError = 1 // the request failed or the server answered with an error
+	ExitUsage = 2 // the command line was invalid
+)
+
+// usageError marks errors caused by a malformed command line.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// App runs one request per call to Run. Terminal tells it whether Out is a
+// terminal, which turns colour on in the default "-color auto" mode.
+type App struct {
+	In         io.Reader
+	Out        io.Writer
+	Err        io.Writer
+	HTTPClient *http.Client
+	Terminal   bool
+}
+
+// headerFlags collects repeated -H flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string     { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(v string) error { *h = append(*h, v); return nil }
+
+// options is a parsed command line.
+type options struct {
+	method  string
+	url     string
+	headers http.Header
+	data    string // as given to -d, before reading @file
+	hasData bool
+	query   query
+	include bool
+	color   bool
+}
+
+// Run performs the request described by args and returns an exit code.
+func (a *App) Run(args []string) int {
+	opts, err := a.parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintln(a.Err, "apiclient:", err)
+		fmt.Fprintln(a.Err, "Run \"apiclient -h\" for usage.")
+		return ExitUsage
+	}
+	if err := a.do(opts); err != nil {
+		fmt.Fprintln(a.Err, "apiclient:", err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			return ExitUsage
+		}
+		return ExitError
+	}
+	return ExitOK
+}
+
+func (a *App) parse(args []string) (*options, error) {
+	fs := flag.NewFlagSet("apiclient", flag.ContinueOnError)
+	fs.SetOutput(a.Err)
+	method := fs.String("X", "", "request method (default GET, or POST with -d)")
+	var headers headerFlags
+	fs.Var(&headers, "H", "request header \"Name: value\" (repeatable)")
+	data := fs.String("d", "", "request body; @file reads it from a file and @- from standard input")
+	expr := fs.String("q", "", "query to extract from the JSON response, e.g. .items[].name")
+	include := fs.Bool("i", false, "print the response status and headers")
+	color := fs.String("color", "auto", "colour JSON output: auto, always or never")
+	fs.Usage = func() {
+		fmt.Fprintln(a.Err, "Usage: apiclient [flags] <url>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, usagef("expected exactly one URL, got %d arguments", fs.NArg())
+	}
+
+	opts := &options{url: fs.Arg(0), headers: http.Header{}, include: *include}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "d" {
+			opts.hasData = true
+		}
+	})
+	opts.data = *data
+
+	opts.method = strings.ToUpper(*method)
+	if opts.method == "" {
+		opts.method = http.MethodGet
+		if opts.hasData {
+			opts.method = http.MethodPost
+		}
+	}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, usagef("header %q must look like \"Name: value\"", h)
+		}
+		opts.headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	q, err := parseQuery(*expr)
+	if err != nil {
+		return nil, &usageError{msg: err.Error()}
+	}
+	opts.query = q
+
+	switch *color {
+	case "auto":
+		opts.color = a.Terminal && os.Getenv("NO_COLOR") == ""
+	case "always":
+		opts.color = true
+	case "never":
+		opts.color = false
+	default:
+		return nil, usagef("-color must be auto, always or never, not %q", *color)
+	}
+	return opts, nil
+}
+
+func (a *App) do(opts *options) error {
+	body, err := a.body(opts)
+	if err != nil {
+		return err
+	}
+
+	clientOpts := []client.Option{}
+	if a.HTTPClient != nil {
+		clientOpts = append(clientOpts, client.WithHTTPClient(a.HTTPClient))
+	}
+	c, err := client.New(opts.url, clientOpts...)
+	if err != nil {
+		return &usageError{msg: err.Error()}
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := c.NewRequest(context.Background(), opts.method, "", reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range opts.headers {
+		req.Header[name] = values
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" && json.Valid(body) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, respBody, err := c.DoRaw(req)
+	if resp == nil {
+		return err
+	}
+	if opts.include {
+		a.writeHead(resp)
+	}
+	if outErr := a.output(opts, respBody); outErr != nil && err == nil {
+		err = outErr
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("%s %s: %s", apiErr.Method, apiErr.URL, apiErr.Status)
+	}
+	return err
+}
+
+// body returns the request body given with -d, or nil without one.
+func (a *App) body(opts *options) ([]byte, error) {
+	if !opts.hasData {
+		return nil, nil
+	}
+	switch {
+	case opts.data == "@-":
+		return io.ReadAll(a.In)
+	case strings.HasPrefix(opts.data, "@"):
+		data, err := os.ReadFile(opts.data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		return data, nil
+	}
+	return []byte(opts.data), nil
+}
+
+func (a *App) writeHead(resp *http.Response) {
+	fmt.Fprintf(a.Out, "%s %s\n", resp.Proto, resp.Status)
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(a.Out, "%s: %s\n", name, value)
+		}
+	}
+	fmt.Fprintln(a.Out)
+}
+
+// output prints the response body: pretty JSON when it is JSON, the raw
+// bytes otherwise, or the values selected by -q.
+func (a *App) output(opts *options, body []byte) error {
+	if opts.query == nil {
+		var buf bytes.Buffer
+		if len(bytes.TrimSpace(body)) > 0 && writePrettyJSON(&buf, body, opts.color) == nil {
+			_, err := buf.WriteTo(a.Out)
+			return err
+		}
+		_, err := a.Out.Write(body)
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("cannot query a response that is not JSON: %w", err)
+	}
+	values, err := opts.query.eval(doc)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		// Plain strings are printed without quotes, like jq -r.
+		if s, ok := v.(string); ok {
+			fmt.Fprintln(a.Out, s)
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := writePrettyJSON(a.Out, data, opts.color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
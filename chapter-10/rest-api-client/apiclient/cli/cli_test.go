@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// echoServer answers with a JSON description of the request it received,
+// or with a fixed error for /fail.
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"no such thing"}`)
+			return
+		}
+		if r.URL.Path == "/text" {
+			io.WriteString(w, "plain text\n")
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"method":       r.Method,
+			"body":         string(body),
+			"content_type": r.Header.Get("Content-Type"),
+			"token":        r.Header.Get("X-Token"),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func run(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	app := &App{In: strings.NewReader(stdin), Out: &out, Err: &errOut}
+	code := app.Run(args)
+	return code, out.String(), errOut.String()
+}
+
+func TestGetPrettyPrints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"zeta":1,"alpha":[true,null,"<b>"],"empty":{},"n":1.50}`)
+	}))
+	defer srv.Close()
+
+	code, out, _ := run(t, "", srv.URL)
+	expected := `{
+  "zeta": 1,
+  "alpha": [
+    true,
+    null,
+    "<b>"
+  ],
+  "empty": {},
+  "n": 1.50
+}
+`
+	if code != ExitOK || out != expected {
+		t.Errorf("expected %q, got %d %q", expected, code, out)
+	}
+
+	_, colored, _ := run(t, "", "-color", "always", srv.URL)
+	if !strings.Contains(colored, colorKey+`"zeta"`+colorReset) || !strings.Contains(colored, colorNumber+"1"+colorReset) {
+		t.Errorf("expected coloured output, got %q", colored)
+	}
+}
+
+func TestMethodHeadersAndBody(t *testing.T) {
+	srv := echoServer(t)
+	file := filepath.Join(t.TempDir(), "body.json")
+	os.WriteFile(file, []byte(`{"title":"from file"}`), 0644)
+
+	tests := []struct {
+		args  []string
+		stdin string
+		query string
+		want  string
+	}{
+		{[]string{"-d", `{"a":1}`}, "", ".method", "POST"},
+		{[]string{"-d", `{"a":1}`}, "", ".content_type", "application/json"},
+		{[]string{"-X", "put", "-d", "@" + file}, "", ".body", `{"title":"from file"}`},
+		{[]string{"-X", "PUT", "-d", "@" + file}, "", ".method", "PUT"},
+		{[]string{"-d", "@-"}, "from stdin", ".body", "from stdin"},
+		{[]string{"-d", "not json"}, "", ".content_type", ""},
+		{[]string{"-d", "x", "-H", "Content-Type: text/plain"}, "", ".content_type", "text/plain"},
+		{[]string{"-H", "X-Token:  secret "}, "", ".token", "secret"},
+		{nil, "", ".method", "GET"},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{}, tt.args...), "-q", tt.query, srv.URL)
+		code, out, errOut := run(t, tt.stdin, args...)
+		if code != ExitOK || out != tt.want+"\n" {
+			t.Errorf("%v: expected %q, got %d %q %s", args, tt.want, code, out, errOut)
+		}
+	}
+}
+
+func TestErrorsAndExitCodes(t *testing.T) {
+	srv := echoServer(t)
+
+	code, out, errOut := run(t, "", srv.URL+"/fail")
+	if code != ExitError || !strings.Contains(out, `"error": "no such thing"`) || !strings.Contains(errOut, "404 Not Found") {
+		t.Errorf("expected the error body and status, got %d %q %q", code, out, errOut)
+	}
+
+	code, out, _ = run(t, "", "-i", srv.URL+"/text")
+	if code != ExitOK || !strings.HasPrefix(out, "HTTP/1.1 200 OK\n") || !strings.HasSuffix(out, "\n\nplain text\n") {
+		t.Errorf("expected the head and the raw body, got %q", out)
+	}
+
+	if code, _, _ := run(t, "", "-q", ".x", srv.URL+"/text"); code != ExitError {
+		t.Errorf("expected querying text to fail, got %d", code)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"a", "b"},
+		{"-H", "no colon", srv.URL},
+		{"-q", "items", srv.URL},
+		{"-color", "sometimes", srv.URL},
+		{"ftp://example.com"},
+		{"-nope", srv.URL},
+	} {
+		if code, _, _ := run(t, "", args...); code != ExitUsage {
+			t.Errorf("%v: expected exit code %d, got %d", args, ExitUsage, code)
+		}
+	}
+	if code, _, errOut := run(t, "", "-d", "@/does/not/exist", srv.URL); code != ExitError || !strings.Contains(errOut, "error reading request body") {
+		t.Errorf("expected a missing body file to fail, got %d %q", code, errOut)
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ANSI colours used for JSON output, in the spirit of jq.
+const (
+	colorReset  = "\x1b[0m"
+	colorKey    = "\x1b[34;1m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[36m"
+	colorLit    = "\x1b[35m" // true, false and null
+)
+
+// prettyPrinter indents JSON token by token, so object keys keep the order
+// the server sent them in.
+type prettyPrinter struct {
+	w     io.Writer
+	color bool
+	err   error
+}
+
+// writePrettyJSON writes each JSON value in data indented, one after the
+// other. It fails if data is not JSON.
+func writePrettyJSON(w io.Writer, data []byte, color bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	p := &prettyPrinter{w: w, color: color}
+	for {
+		if err := p.value(dec, 0); err != nil {
+			if errors.Is(err, io.EOF) {
+				return p.err
+			}
+			return err
+		}
+		p.write("\n")
+	}
+}
+
+func (p *prettyPrinter) value(dec *json.Decoder, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		return p.container(dec, v, depth)
+	case string:
+		p.colored(colorString, quote(v))
+	case json.Number:
+		p.colored(colorNumber, v.String())
+	case bool:
+		if v {
+			p.colored(colorLit, "true")
+		} else {
+			p.colored(colorLit, "false")
+		}
+	case nil:
+		p.colored(colorLit, "null")
+	}
+	return nil
+}
+
+func (p *prettyPrinter) container(dec *json.Decoder, open json.Delim, depth int) error {
+	closing := "]"
+	if open == '{' {
+		closing = "}"
+	}
+	if !dec.More() {
+		dec.Token()
+		p.write(string(open) + closing)
+		return nil
+	}
+
+	p.write(string(open) + "\n")
+	for dec.More() {
+		p.indent(depth + 1)
+		if open == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			p.colored(colorKey, quote(key.(string)))
+			p.write(": ")
+		}
+		if err := p.value(dec, depth+1); err != nil {
+			return err
+		}
+		if dec.More() {
+			p.write(",")
+		}
+		p.write("\n")
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	p.indent(depth)
+	p.write(closing)
+	return nil
+}
+
+func (p *prettyPrinter) colored(color, s string) {
+	if p.color {
+		p.write(color + s + colorReset)
+	} else {
+		p.write(s)
+	}
+}
+
+func (p *prettyPrinter) indent(depth int) {
+	p.write(strings.Repeat("  ", depth))
+}
+
+func (p *prettyPrinter) write(s string) {
+	if p.err == nil {
+		_, p.err = io.WriteString(p.w, s)
+	}
+}
+
+// quote returns s as a JSON string without escaping HTML characters.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A query is a jq-style path such as ".items[0].name" or ".items[].id". It
+// is a list of steps applied in turn to every current value; "[]" fans out
+// over all elements, so a query may yield several values. A leading "$" is
+// accepted as well, for JSONPath habits ("$.items[0]").
+type query []step
+
+type stepKind int
+
+const (
+	stepField stepKind = iota // .name or ["name"]
+	stepIndex                 // [n], counting from the end when negative
+	stepEach                  // []
+)
+
+type step struct {
+	kind  stepKind
+	field string
+	index int
+}
+
+func (s step) String() string {
+	switch s.kind {
+	case stepField:
+		return "." + s.field
+	case stepIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return "[]"
+}
+
+func parseQuery(expr string) (query, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+	if rest == "" || rest == "." {
+		return nil, nil
+	}
+	if rest[0] != '.' && rest[0] != '[' {
+		return nil, fmt.Errorf("query %q must start with '.'", expr)
+	}
+
+	var q query
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "[]"):
+			q = append(q, step{kind: stepEach})
+			rest = rest[2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("query %q: missing ']'", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			if strings.HasPrefix(inner, `"`) {
+				name, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("query %q: bad key %s", expr, inner)
+				}
+				q = append(q, step{kind: stepField, field: name})
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("query %q: bad index %q", expr, inner)
+				}
+				q = append(q, step{kind: stepIndex, index: n})
+			}
+			rest = rest[end+1:]
+		case rest[0] == '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				if rest != "" && rest[0] == '[' {
+					continue // ".[0]" is the same as "[0]"
+				}
+				return nil, fmt.Errorf("query %q: missing field name after '.'", expr)
+			}
+			q = append(q, step{kind: stepField, field: rest[:end]})
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("query %q: unexpected %q", expr, rest)
+		}
+	}
+	return q, nil
+}
+
+// eval applies the query to a value decoded by encoding/json. Like jq, a
+// missing field or index yields null rather than an error.
+func (q query) eval(v any) ([]any, error) {
+	values := []any{v}
+	for _, s := range q {
+		var next []any
+		for _, v := range values {
+			out, err := s.apply(v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func (s step) apply(v any) ([]any, error) {
+	if v == nil && s.kind != stepEach {
+		return []any{nil}, nil
+	}
+	switch s.kind {
+	case stepField:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot get %s of %s", s, typeName(v))
+		}
+		return []any{obj[s.field]}, nil
+	case stepIndex:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot get %s of %s", s, typeName(v))
+		}
+		i := s.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return []any{nil}, nil
+		}
+		return []any{arr[i]}, nil
+	}
+	switch v := v.(type) {
+	case []any:
+		return v, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]any, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, v[k])
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number, float64:
+		return "a number"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	doc := `{"items":[{"id":1,"name":"a","tags":["x"]},{"id":2,"name":"b"}],"odd key":{"v":true},"meta":null}`
+	tests := []struct {
+		expr string
+		want string // values as compact JSON, one per line
+	}{
+		{".", `{"items":[{"id":1,"name":"a","tags":["x"]},{"id":2,"name":"b"}],"meta":null,"odd key":{"v":true}}`},
+		{"$.items[0].name", `"a"`},
+		{".items[-1].id", `2`},
+		{".items[5]", `null`},
+		{".items[].id", "1\n2"},
+		{".items[].tags[0]", "\"x\"\nnull"},
+		{`.["odd key"].v`, `true`},
+		{".missing.deeper", `null`},
+		{".meta[0]", `null`},
+		{".items.[1].name", `"b"`},
+		{"[\"odd key\"][]", `true`},
+	}
+	for _, tt := range tests {
+		q, err := parseQuery(tt.expr)
+		if err != nil {
+			t.Errorf("parseQuery(%q): %v", tt.expr, err)
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(doc))
+		dec.UseNumber()
+		var v any
+		dec.Decode(&v)
+		values, err := q.eval(v)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, v := range values {
+			data, _ := json.Marshal(v)
+			got = append(got, string(data))
+		}
+		if strings.Join(got, "\n") != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, expr := range []string{"items", ".items[", ".items[x]", `.["unterminated]`, ".a..b"} {
+		if _, err := parseQuery(expr); err == nil {
+			t.Errorf("parseQuery(%q): expected an error", expr)
+		}
+	}
+
+	var v any
+	json.Unmarshal([]byte(`{"s":"text","n":1}`), &v)
+	for _, expr := range []string{".s.x", ".s[0]", ".n[]"} {
+		q, _ := parseQuery(expr)
+		if _, err := q.eval(v); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import "net/http"
+
+// Authenticator adds credentials to a request before it is sent.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthFunc lets an ordinary function act as an Authenticator.
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken sends "Authorization: Bearer <token>".
+func BearerToken(token string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// BasicAuth sends HTTP basic authentication.
+func BasicAuth(username, password string) Authenticator {
+	return AuthFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// APIKey sends key in the given header, e.g. "X-API-Key". The
+// Authenticator has a HeaderName method returning header, which the Client
+// uses to drop the key on redirects to another host and
+// cassette.Recorder.RedactAuth uses to keep it out of fixtures.
+func APIKey(header, key string) Authenticator {
+	return apiKey{header: header, key: key}
+}
+
+type apiKey struct {
+	header, key string
+}
+
+func (a apiKey) Authenticate(req *http.Request) error {
+	req.Header.Set(a.header, a.key)
+	return nil
+}
+
+func (a apiKey) HeaderName() string {
+	return a.header
+}
+
+// APIKeyQuery sends key as the given query parameter, e.g. "api_key". The
+// Authenticator has a QueryParam method returning param, which
+// cassette.Recorder.RedactAuth uses to keep the key out of fixtures.
+func APIKeyQuery(param, key string) Authenticator {
+	return apiKeyQuery{param: param, key: key}
+}
+
+type apiKeyQuery struct {
+	param, key string
+}
+
+func (a apiKeyQuery) Authenticate(req *http.Request) error {
+	q := req.URL.Query()
+	q.Set(a.param, a.key)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+func (a apiKeyQuery) QueryParam() string {
+	return a.param
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"apiclient/cassette"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// replayClient returns a client whose responses come from a recorded
+// cassette instead of the network.
+func replayClient(t *testing.T, name string) *Client {
+	t.Helper()
+	rec, err := cassette.New("testdata/"+name, cassette.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New("https://jsonplaceholder.typicode.com",
+		WithHTTPClient(&http.Client{Transport: rec}),
+		WithAuth(BearerToken("not-recorded")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReplayedAPI(t *testing.T) {
+	c := replayClient(t, "jsonplaceholder.json")
+	ctx := context.Background()
+
+	todo, err := Get[Todo](ctx, c, "todos/1")
+	if err != nil || todo.Title != "delectus aut autem" {
+		t.Errorf("unexpected todo %+v, %v", todo, err)
+	}
+
+	if _, err := Get[Todo](ctx, c, "todos/999"); !IsNotFound(err) {
+		t.Errorf("expected a 404 APIError, got %v", err)
+	}
+
+	opts := PageOptions{Pager: OffsetLimit{OffsetParam: "_start", LimitParam: "_limit", Limit: 2}}
+	var ids []int
+	for todo, err := range Paginate[Todo](ctx, c, "todos", opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, todo.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("expected todos 1 to 3, got %v", ids)
+	}
+}
+
+func TestRecordedAPIKeyIsRedacted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{}")
+	}))
+	defer srv.Close()
+
+	auth := APIKeyQuery("sig", "my-key")
+	path := filepath.Join(t.TempDir(), "api.json")
+	rec, err := cassette.New(path, cassette.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.RedactAuth(auth)
+	c, _ := New(srv.URL, WithHTTPClient(&http.Client{Transport: rec}), WithAuth(auth))
+	if _, err := Get[map[string]any](context.Background(), c, "items"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "my-key") {
+		t.Errorf("cassette contains the API key:\n%s", data)
+	}
+}
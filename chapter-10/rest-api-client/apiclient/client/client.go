@@ -1,42 +1,251 @@
+// Package client is a small client for JSON REST APIs. A Client holds the
+// base URL, default headers and authentication of one API; the generic Get,
+// Post and Send functions decode responses into any type.
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
-type Todo struct {
-	UserID    int    `json:"userId"`
-	ID        int    `json:"id"`
-	Title     string `json:"title"`
-	Completed bool   `json:"completed"`
+// maxErrorBody limits how much of a failed response is kept in an APIError.
+const maxErrorBody = 64 << 10
+
+// Client sends requests to one API. It is safe for concurrent use once
+// configured.
+type Client struct {
+	base       *url.URL // as given to New
+	baseURL    *url.URL // with a trailing slash, for resolving paths
+	httpClient *http.Client
+	header     http.Header
+	auth       Authenticator
+}
+
+// Option configures a Client in New.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests with hc instead of
+// http.DefaultClient, e.g. to set a timeout or transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// WithAuth authenticates every request with a.
+func WithAuth(a Authenticator) Option {
+	return func(c *Client) { c.auth = a }
 }
 
-func FetchAndPrintData(url string) (*Todo, error) {
-	resp, err := http.Get(url)
+// New returns a client for the API at baseURL. Request paths are resolved
+// against it, so with a base of "https://example.com/v1" the path "todos/1"
+// (or "/todos/1") means "https://example.com/v1/todos/1".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	dir := *u
+	if !strings.HasSuffix(dir.Path, "/") {
+		dir.Path += "/"
+	}
+	c := &Client{
+		base:       u,
+		baseURL:    &dir,
+		httpClient: http.DefaultClient,
+		header:     http.Header{"Accept": {"application/json"}},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if a, ok := c.auth.(interface{ HeaderName() string }); ok {
+		hc := *c.httpClient
+		hc.CheckRedirect = dropHeaderOnRedirect(a.HeaderName(), hc.CheckRedirect)
+		c.httpClient = &hc
+	}
+	return c, nil
+}
+
+// dropHeaderOnRedirect wraps the redirect policy next so that header is
+// removed when a redirect leaves the scheme and host of the first request.
+// net/http does this itself only for Authorization and cookies.
+func dropHeaderOnRedirect(header string, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !sameOrigin(req.URL, via[0].URL) {
+			req.Header.Del(header)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// URL resolves path against the base URL. An empty path is the base URL
+// itself and absolute URLs are returned as is.
+func (c *Client) URL(path string) (*url.URL, error) {
+	if path == "" {
+		u := *c.base
+		return &u, nil
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	if !ref.IsAbs() {
+		ref.Path = strings.TrimPrefix(ref.Path, "/")
+	}
+	return c.baseURL.ResolveReference(ref), nil
+}
+
+// NewRequest builds a request for path with the client's headers and
+// authentication. A body that is an io.Reader is sent as is; any other
+// non-nil body is encoded as JSON. Absolute URLs with another scheme or
+// host than the base URL are not authenticated, so credentials are not
+// sent to a server a response merely links to. Redirects to another host
+// lose the Authorization header and the header of an APIKey authenticator.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	u, err := c.URL(path)
+	if err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non 200 status code: %d", resp.StatusCode)
+	var reader io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+		isJSON = true
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
+	for key, values := range c.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil && sameOrigin(u, c.baseURL) {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("error authenticating request: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// redactURL returns u as a string with the value of the authenticator's
+// query parameter, if it has one, replaced by "REDACTED", so errors can be
+// logged without leaking an APIKeyQuery key.
+func (c *Client) redactURL(u *url.URL) string {
+	a, ok := c.auth.(interface{ QueryParam() string })
+	if !ok || u.RawQuery == "" {
+		return u.String()
+	}
+	q := u.Query()
+	if !q.Has(a.QueryParam()) {
+		return u.String()
+	}
+	q.Set(a.QueryParam(), "REDACTED")
+	redacted := *u
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
+
+// sameOrigin reports whether a and b have the same scheme and host.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
 
-	var todo Todo
-	if err := json.Unmarshal(data, &todo); err != nil {
-		return nil, fmt.Errorf("error unmarshalling json %w", err)
+// Do sends req and decodes a successful JSON response into out, unless out
+// is nil or the response has no body. A response outside the 2xx range is
+// returned as an *APIError.
+func (c *Client) Do(req *http.Request, out any) (*http.Response, error) {
+	resp, body, err := c.DoRaw(req)
+	if err != nil || out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return resp, err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return resp, fmt.Errorf("error decoding response from %s: %w", c.redactURL(req.URL), err)
 	}
+	return resp, nil
+}
+
+// DoRaw sends req and returns the response with its whole body, which has
+// already been read and closed. A response outside the 2xx range is
+// returned as an *APIError.
+func (c *Client) DoRaw(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = c.redactURL(req.URL)
+		}
+		return nil, nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp, body, &APIError{
+			Method:     req.Method,
+			URL:        c.redactURL(req.URL),
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+			Body:       body,
+		}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	return resp, body, nil
+}
+
+// Send sends a request with the given method and body to path and decodes
+// the response into a T.
+func Send[T any](ctx context.Context, c *Client, method, path string, body any) (T, error) {
+	var out T
+	req, err := c.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return out, err
+	}
+	_, err = c.Do(req, &out)
+	return out, err
+}
+
+// Get fetches path and decodes the response into a T.
+func Get[T any](ctx context.Context, c *Client, path string) (T, error) {
+	return Send[T](ctx, c, http.MethodGet, path, nil)
+}
 
-	fmt.Printf("Todo ID: %d\n", todo.ID)
-	fmt.Printf("Title: %s\n", todo.Title)
-	fmt.Printf("Completed: %t\n", todo.Completed)
-	return &todo, nil
+// Post sends body to path and decodes the response into a T.
+func Post[T any](ctx context.Context, c *Client, path string, body any) (T, error) {
+	return Send[T](ctx, c, http.MethodPost, path, body)
 }
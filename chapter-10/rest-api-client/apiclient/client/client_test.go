@@ -1,41 +1,231 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestFetchAndPrintDataSuccess(t *testing.T) {
+type Todo struct {
+	UserID    int    `json:"userId"`
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Completed bool   `json:"completed"`
+}
+
+func TestGetSuccess(t *testing.T) {
 	// Create a mock server
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+		if r.URL.Path != "/v1/todos/1" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.Header.Get("Accept") != "application/json" || r.Header.Get("X-Team") != "core" {
+			t.Errorf("missing default headers: %v", r.Header)
+		}
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
 		fmt.Fprintln(w, `{"userId": 1, "id": 1, "title": "Test Todo", "completed": false}`)
 	}))
 	defer testServer.Close()
 
-	// Call the function with the mock server URL
-	todo, err := FetchAndPrintData(testServer.URL)
+	c, err := New(testServer.URL+"/v1", WithHeader("X-Team", "core"))
 	if err != nil {
-		t.Errorf("FetchAndPrintData() failed with error: %v", err)
-		return
+		t.Fatal(err)
+	}
+	todo, err := Get[Todo](context.Background(), c, "/todos/1")
+	if err != nil {
+		t.Fatalf("Get() failed with error: %v", err)
 	}
-
 	if todo.Title != "Test Todo" {
 		t.Fatalf("Returned incorrect todo item, expected title Test Todo, got %s", todo.Title)
 	}
 }
 
-func TestFetchAndPrintDataFailure(t *testing.T) {
+func TestGetFailure(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": "boom"}`)
+	}))
+	defer testServer.Close()
+
+	c, _ := New(testServer.URL)
+	_, err := Get[Todo](context.Background(), c, "todos/1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusInternalServerError || string(apiErr.Body) != `{"error": "boom"}` || apiErr.Method != http.MethodGet {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if StatusCode(err) != http.StatusInternalServerError || IsNotFound(err) {
+		t.Errorf("unexpected status helpers for %v", err)
+	}
+}
+
+func TestErrorsHideAPIKey(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			fmt.Fprint(w, "not json")
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, strings.Repeat("x", 10000))
+	}))
+	defer testServer.Close()
+
+	c, _ := New(testServer.URL, WithAuth(APIKeyQuery("sig", "s3cret")))
+	for _, path := range []string{"todos?page=2", "bad"} {
+		_, err := Get[Todo](context.Background(), c, path)
+		if err == nil || strings.Contains(err.Error(), "s3cret") || !strings.Contains(err.Error(), "sig=REDACTED") {
+			t.Errorf("%s: expected an error with the key redacted, got %v", path, err)
+		}
+	}
+	_, err := Get[Todo](context.Background(), c, "todos")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Body) != 10000 || len(err.Error()) > maxErrorText+200 {
+		t.Errorf("expected the full body but a short message, got %d bytes in %d", len(apiErr.Body), len(err.Error()))
+	}
+	if strings.Contains(apiErr.URL, "s3cret") {
+		t.Errorf("expected a redacted URL, got %s", apiErr.URL)
+	}
+
+	c, _ = New("http://127.0.0.1:1", WithAuth(APIKeyQuery("sig", "s3cret")))
+	if _, err := Get[Todo](context.Background(), c, "todos"); err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("expected a transport error without the key, got %v", err)
+	}
+}
+
+func TestPost(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %v", r.Method, r.Header)
+		}
+		var todo Todo
+		json.NewDecoder(r.Body).Decode(&todo)
+		todo.ID = 201
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(todo)
+	}))
+	defer testServer.Close()
+
+	c, _ := New(testServer.URL)
+	created, err := Post[Todo](context.Background(), c, "todos", Todo{Title: "write tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 201 || created.Title != "write tests" {
+		t.Errorf("unexpected todo %+v", created)
+	}
+
+	// A raw reader is sent untouched and an empty response decodes to the
+	// zero value.
+	emptyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "" {
+			t.Errorf("expected no content type for a raw body, got %q", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer emptyServer.Close()
+	c, _ = New(emptyServer.URL)
+	if got, err := Post[Todo](context.Background(), c, "", strings.NewReader("raw")); err != nil || got != (Todo{}) {
+		t.Errorf("expected a zero todo, got %+v, %v", got, err)
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  Authenticator
+		check func(r *http.Request) bool
+	}{
+		{"bearer", BearerToken("tok"), func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer tok"
+		}},
+		{"basic", BasicAuth("ann", "secret"), func(r *http.Request) bool {
+			user, pass, ok := r.BasicAuth()
+			return ok && user == "ann" && pass == "secret"
+		}},
+		{"api key", APIKey("X-API-Key", "k1"), func(r *http.Request) bool {
+			return r.Header.Get("X-API-Key") == "k1"
+		}},
+		{"api key query", APIKeyQuery("api_key", "k2"), func(r *http.Request) bool {
+			return r.URL.Query().Get("api_key") == "k2" && r.URL.Query().Get("page") == "2"
+		}},
+	}
+	for _, tt := range tests {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !tt.check(r) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		c, _ := New(testServer.URL, WithAuth(tt.auth))
+		if _, err := Get[any](context.Background(), c, "items?page=2"); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		testServer.Close()
+	}
+}
+
+func TestAPIKeyDroppedOnRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "" {
+			t.Errorf("expected no API key on another host, got %q", r.Header.Get("X-API-Key"))
+		}
+		fmt.Fprint(w, "{}")
+	}))
+	defer other.Close()
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "k1" {
+			t.Errorf("expected the API key on the API host, got %q", r.Header.Get("X-API-Key"))
+		}
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/items", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer testServer.Close()
+
+	c, _ := New(testServer.URL, WithAuth(APIKey("X-API-Key", "k1")))
+	if _, err := Get[any](context.Background(), c, "moved"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
 	}))
 	defer testServer.Close()
 
-	_, err := FetchAndPrintData(testServer.URL)
-	if err == nil {
-		t.Fatalf("FetchAndPrintData should have failed with 500")
+	c, _ := New(testServer.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := Get[Todo](ctx, c, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	c, _ := New("https://api.example.com/v1")
+	tests := map[string]string{
+		"":                        "https://api.example.com/v1",
+		"todos":                   "https://api.example.com/v1/todos",
+		"/todos?page=2":           "https://api.example.com/v1/todos?page=2",
+		"https://other.example/x": "https://other.example/x",
+	}
+	for path, want := range tests {
+		if got, err := c.URL(path); err != nil || got.String() != want {
+			t.Errorf("URL(%q) = %v, %v; expected %s", path, got, err, want)
+		}
+	}
+	if _, err := New("ftp://example.com"); err == nil {
+		t.Error("expected an error for a non-HTTP base URL")
 	}
 }
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// maxErrorText limits how much of Body an APIError's message includes.
+const maxErrorText = 512
+
+// APIError is returned for responses with a status outside the 2xx range.
+// Body holds the start of the response body, which usually explains the
+// error. URL never contains the key of an APIKeyQuery authenticator.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	}
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.URL, e.Status, errorText(e.Body))
+}
+
+// errorText returns body for an error message, cut after maxErrorText
+// bytes on a character boundary.
+func errorText(body []byte) string {
+	if len(body) <= maxErrorText {
+		return string(body)
+	}
+	n := maxErrorText
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return string(body[:n]) + "..."
+}
+
+// StatusCode returns the HTTP status of an *APIError in err's chain, or 0
+// if there is none.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is an *APIError with status 404.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
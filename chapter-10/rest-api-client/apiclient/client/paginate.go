@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxPages is the page limit used when PageOptions.MaxPages is zero.
+const DefaultMaxPages = 100
+
+// ErrTooManyPages is yielded when a listing has more pages than allowed by
+// PageOptions.MaxPages.
+var ErrTooManyPages = errors.New("too many pages")
+
+// Paginator knows how an API links one page of results to the next.
+type Paginator interface {
+	// First returns the URL of the first page, given the URL of the listing.
+	First(u *url.URL) *url.URL
+	// Next returns the URL of the page after the one fetched from u, or nil
+	// after the last page. body is the raw response and items the number of
+	// items it held.
+	Next(u *url.URL, resp *http.Response, body []byte, items int) (*url.URL, error)
+}
+
+// PageOptions configures Paginate.
+type PageOptions struct {
+	Pager Paginator
+	// ItemsField names the top-level field of each page that holds the
+	// items. When empty, each page must be a JSON array of items.
+	ItemsField string
+	// MaxPages stops runaway listings; zero means DefaultMaxPages.
+	MaxPages int
+}
+
+// Paginate lists the items at path page by page. Pages are only fetched as
+// the loop consumes the items of the previous one, and breaking out of the
+// loop stops fetching. An error ends the sequence:
+//
+//	for todo, err := range client.Paginate[Todo](ctx, c, "todos", opts) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Paginate[T any](ctx context.Context, c *Client, path string, opts PageOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if opts.Pager == nil {
+			yield(zero, errors.New("no Paginator given"))
+			return
+		}
+		maxPages := opts.MaxPages
+		if maxPages <= 0 {
+			maxPages = DefaultMaxPages
+		}
+		start, err := c.URL(path)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		u := opts.Pager.First(start)
+		for pages := 0; u != nil; pages++ {
+			if pages == maxPages {
+				yield(zero, fmt.Errorf("%w: stopped after %d", ErrTooManyPages, maxPages))
+				return
+			}
+			req, err := c.NewRequest(ctx, http.MethodGet, u.String(), nil)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			resp, body, err := c.DoRaw(req)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			items, err := pageItems[T](body, opts.ItemsField)
+			if err != nil {
+				yield(zero, fmt.Errorf("error decoding page %s: %w", u, err))
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			next, err := opts.Pager.Next(u, resp, body, len(items))
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if next != nil && next.String() == u.String() {
+				yield(zero, fmt.Errorf("page %s links to itself", u))
+				return
+			}
+			u = next
+		}
+	}
+}
+
+func pageItems[T any](body []byte, field string) ([]T, error) {
+	var items []T
+	if field == "" {
+		return items, json.Unmarshal(body, &items)
+	}
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, err
+	}
+	raw, ok := page[field]
+	if !ok {
+		return nil, fmt.Errorf("page has no %q field", field)
+	}
+	return items, json.Unmarshal(raw, &items)
+}
+
+// LinkHeader follows RFC 5988 "Link" headers with rel="next", as used by
+// GitHub and many other APIs.
+type LinkHeader struct{}
+
+func (LinkHeader) First(u *url.URL) *url.URL { return u }
+
+func (LinkHeader) Next(u *url.URL, resp *http.Response, body []byte, items int) (*url.URL, error) {
+	next, ok := parseLinks(resp.Header.Values("Link"))["next"]
+	if !ok {
+		return nil, nil
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next link %q: %w", next, err)
+	}
+	return u.ResolveReference(ref), nil
+}
+
+// parseLinks maps each relation type in Link header values to its target,
+// e.g. `<https://api.example.com/x?page=2>; rel="next"`. Commas only
+// separate links outside the angle brackets and quoted parameters, so a
+// target may contain them.
+func parseLinks(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, rest := range values {
+		for {
+			start := strings.IndexByte(rest, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(rest[start:], '>')
+			if end < 0 {
+				break
+			}
+			target := rest[start+1 : start+end]
+			var params string
+			params, rest = cutLinkParams(rest[start+end+1:])
+			for _, param := range strings.Split(params, ";") {
+				name, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(val, `"`)) {
+					links[strings.ToLower(rel)] = target
+				}
+			}
+		}
+	}
+	return links
+}
+
+// cutLinkParams splits s at the first comma outside a quoted string,
+// returning the parameters of one link and the links after it.
+func cutLinkParams(s string) (params, rest string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				return s[:i], s[i+1:]
+			}
+		}
+	}
+	return s, ""
+}
+
+// OffsetLimit pages with offset and limit query parameters. A page with
+// fewer than Limit items is taken to be the last.
+type OffsetLimit struct {
+	OffsetParam string // "offset" if empty
+	LimitParam  string // "limit" if empty
+	Limit       int
+}
+
+func (p OffsetLimit) First(u *url.URL) *url.URL {
+	return p.page(u, 0)
+}
+
+func (p OffsetLimit) Next(u *url.URL, resp *http.Response, body []byte, items int) (*url.URL, error) {
+	if items == 0 || items < p.Limit {
+		return nil, nil
+	}
+	offset, _ := strconv.Atoi(u.Query().Get(p.offsetParam()))
+	return p.page(u, offset+items), nil
+}
+
+func (p OffsetLimit) page(u *url.URL, offset int) *url.URL {
+	next := *u
+	q := next.Query()
+	q.Set(p.offsetParam(), strconv.Itoa(offset))
+	if p.Limit > 0 {
+		q.Set(p.limitParam(), strconv.Itoa(p.Limit))
+	}
+	next.RawQuery = q.Encode()
+	return &next
+}
+
+func (p OffsetLimit) offsetParam() string {
+	if p.OffsetParam == "" {
+		return "offset"
+	}
+	return p.OffsetParam
+}
+
+func (p OffsetLimit) limitParam() string {
+	if p.LimitParam == "" {
+		return "limit"
+	}
+	return p.LimitParam
+}
+
+// Cursor pages with an opaque token: each page carries the token of the
+// next one in a top-level field, which is sent back as a query parameter.
+// An empty, null or missing token ends the listing.
+type Cursor struct {
+	Param string // "cursor" if empty
+	Field string // "next_cursor" if empty
+}
+
+func (p Cursor) First(u *url.URL) *url.URL { return u }
+
+func (p Cursor) Next(u *url.URL, resp *http.Response, body []byte, items int) (*url.URL, error) {
+	field, param := p.Field, p.Param
+	if field == "" {
+		field = "next_cursor"
+	}
+	if param == "" {
+		param = "cursor"
+	}
+
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("error reading cursor: %w", err)
+	}
+	// Numbers are kept as written, so large integer cursors are not
+	// rounded to float64.
+	var token any
+	if raw, ok := page[field]; ok {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&token); err != nil {
+			return nil, fmt.Errorf("error reading cursor: %w", err)
+		}
+	}
+	var cursor string
+	switch t := token.(type) {
+	case nil:
+	case string:
+		cursor = t
+	case json.Number:
+		cursor = t.String()
+	default:
+		return nil, fmt.Errorf("cursor field %q is not a string", field)
+	}
+	if cursor == "" {
+		return nil, nil
+	}
+
+	next := *u
+	q := next.Query()
+	q.Set(param, cursor)
+	next.RawQuery = q.Encode()
+	return &next, nil
+}
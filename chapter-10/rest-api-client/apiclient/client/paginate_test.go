@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// numbers serves the items 1..total through handler, counting requests.
+type numbers struct {
+	total    int
+	requests int
+}
+
+func (n *numbers) slice(from, count int) []int {
+	var items []int
+	for i := from; i < from+count && i < n.total; i++ {
+		items = append(items, i+1)
+	}
+	return items
+}
+
+func collect(t *testing.T, seq func(func(int, error) bool)) ([]int, error) {
+	t.Helper()
+	var got []int
+	for item, err := range seq {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, item)
+	}
+	return got, nil
+}
+
+func expectRange(t *testing.T, got []int, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("expected %d items, got %v", n, got)
+	}
+	for i, v := range got {
+		if v != i+1 {
+			t.Fatalf("expected items 1..%d in order, got %v", n, got)
+		}
+	}
+}
+
+func TestPaginateLinkHeader(t *testing.T) {
+	n := &numbers{total: 7}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page*3 < n.total {
+			// Relative links are resolved against the current page.
+			w.Header().Add("Link", fmt.Sprintf(`</api/items?page=%d>; rel="next", </api/items?page=3>; rel="last"`, page+1))
+		}
+		json.NewEncoder(w).Encode(n.slice((page-1)*3, 3))
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL + "/api")
+	got, err := collect(t, Paginate[int](context.Background(), c, "items", PageOptions{Pager: LinkHeader{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRange(t, got, 7)
+	if n.requests != 3 {
+		t.Errorf("expected 3 requests, got %d", n.requests)
+	}
+
+	// Pages are fetched lazily.
+	n.requests = 0
+	for item := range Paginate[int](context.Background(), c, "items", PageOptions{Pager: LinkHeader{}}) {
+		if item == 2 {
+			break
+		}
+	}
+	if n.requests != 1 {
+		t.Errorf("expected breaking early to fetch 1 page, got %d", n.requests)
+	}
+}
+
+func TestPaginateLinkToOtherHost(t *testing.T) {
+	var otherAuth []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = append(otherAuth, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode([]int{2})
+	}))
+	defer other.Close()
+	var apiAuth []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = append(apiAuth, r.Header.Get("Authorization"))
+		w.Header().Add("Link", fmt.Sprintf(`<%s/items?page=2>; rel="next"`, other.URL))
+		json.NewEncoder(w).Encode([]int{1})
+	}))
+	defer api.Close()
+
+	c, _ := New(api.URL, WithAuth(BearerToken("secret")))
+	got, err := collect(t, Paginate[int](context.Background(), c, "items", PageOptions{Pager: LinkHeader{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRange(t, got, 2)
+	if len(apiAuth) != 1 || apiAuth[0] != "Bearer secret" {
+		t.Errorf("expected the API to be authenticated, got %q", apiAuth)
+	}
+	if len(otherAuth) != 1 || otherAuth[0] != "" {
+		t.Errorf("expected no credentials for the other host, got %q", otherAuth)
+	}
+}
+
+func TestPaginateOffsetLimit(t *testing.T) {
+	n := &numbers{total: 10}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("take"))
+		if r.URL.Query().Get("sort") != "id" {
+			t.Errorf("expected the original query to be kept, got %q", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": n.slice(offset, limit)})
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	opts := PageOptions{
+		Pager:      OffsetLimit{OffsetParam: "skip", LimitParam: "take", Limit: 5},
+		ItemsField: "data",
+	}
+	got, err := collect(t, Paginate[int](context.Background(), c, "items?sort=id", opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRange(t, got, 10)
+	// Two full pages, then an empty one marks the end.
+	if n.requests != 3 {
+		t.Errorf("expected 3 requests, got %d", n.requests)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	n := &numbers{total: 5}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		page := map[string]any{"items": n.slice(from, 2), "next_cursor": nil}
+		if from+2 < n.total {
+			page["next_cursor"] = strconv.Itoa(from + 2)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	got, err := collect(t, Paginate[int](context.Background(), c, "items", PageOptions{Pager: Cursor{}, ItemsField: "items"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRange(t, got, 5)
+}
+
+func TestPaginateGuards(t *testing.T) {
+	// A server whose pages never end.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Query().Get("loop") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<?page=%d>; rel="next"`, page+1))
+		} else {
+			w.Header().Set("Link", `<?loop=1>; rel="next"`)
+		}
+		json.NewEncoder(w).Encode([]int{page})
+	}))
+	defer srv.Close()
+	c, _ := New(srv.URL)
+
+	got, err := collect(t, Paginate[int](context.Background(), c, "", PageOptions{Pager: LinkHeader{}, MaxPages: 4}))
+	if !errors.Is(err, ErrTooManyPages) || len(got) != 4 {
+		t.Errorf("expected ErrTooManyPages after 4 pages, got %v, %v", got, err)
+	}
+
+	if _, err := collect(t, Paginate[int](context.Background(), c, "?loop=1", PageOptions{Pager: LinkHeader{}})); err == nil {
+		t.Error("expected an error for a page linking to itself")
+	}
+
+	if _, err := collect(t, Paginate[int](context.Background(), c, "", PageOptions{})); err == nil {
+		t.Error("expected an error without a Paginator")
+	}
+
+	if _, err := collect(t, Paginate[int](context.Background(), c, "", PageOptions{Pager: Cursor{}, ItemsField: "items"})); err == nil {
+		t.Error("expected an error when the items field is missing")
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	links := parseLinks([]string{
+		`<https://a.example/x?page=2>; rel="next", <https://a.example/x?page=9>; rel="last"`,
+		`<https://a.example/x?page=1>; REL="prev first"`,
+		`garbage`,
+		`<https://a.example/x?ids=1,2&page=3>; title="a, b"; rel="alternate", <https://a.example/y>; rel=up`,
+	})
+	want := map[string]string{
+		"alternate": "https://a.example/x?ids=1,2&page=3",
+		"up":        "https://a.example/y",
+		"next":      "https://a.example/x?page=2",
+		"last":      "https://a.example/x?page=9",
+		"prev":      "https://a.example/x?page=1",
+		"first":     "https://a.example/x?page=1",
+	}
+	if fmt.Sprint(links) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, links)
+	}
+}
+
+func TestCursorLargeNumber(t *testing.T) {
+	u, _ := url.Parse("https://a.example/items")
+	next, err := Cursor{}.Next(u, nil, []byte(`{"next_cursor": 9007199254740993}`), 1)
+	if err != nil || next.Query().Get("cursor") != "9007199254740993" {
+		t.Errorf("expected cursor 9007199254740993, got %v, %v", next, err)
+	}
+}
@@ -1,21 +1,24 @@
 package main
 
 import (
-	"apiclient/client"
-	"fmt"
-	"log"
+	"apiclient/cli"
+	"net/http"
 	"os"
+	"time"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: go run main.go <url>")
-		os.Exit(1)
+	app := &cli.App{
+		In:         os.Stdin,
+		Out:        os.Stdout,
+		Err:        os.Stderr,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Terminal:   isTerminal(os.Stdout),
 	}
-	url := os.Args[1]
+	os.Exit(app.Run(os.Args[1:]))
+}
 
-	_, err := client.FetchAndPrintData(url)
-	if err != nil {
-		log.Fatal("error fetching data", err)
-	}
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"os"
 	"structprinter/printer"
 )
 
@@ -14,6 +17,16 @@ type Person struct {
 	Name    string
 	Age     int
 	Address Address
+	Tags    []string
+	Manager *Person
+}
+
+type Config struct {
+	Addr     string
+	User     string `print:"name=user"`
+	Password string `print:"redact"`
+	Debug    bool   `print:"-"`
+	token    string
 }
 
 func main() {
@@ -24,7 +37,58 @@ func main() {
 			Street: "Main Street 123",
 			City:   "New York",
 		},
+		Tags: []string{"admin", "ops"},
 	}
 	fmt.Println("Printing the fields of a struct using reflection")
 	printer.PrintStructFields(person)
+
+	fmt.Println("\nPretty-printing the same value")
+	out, err := printer.Sprint(person, printer.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(out)
+
+	fmt.Println("\nPrinting a config without its secrets")
+	cfg := Config{Addr: ":8080", User: "admin", Password: "s3cret", token: "abc"}
+	out, err = printer.Sprint(cfg, printer.Options{HideUnexported: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(out)
+
+	fmt.Println("\nComparing two people")
+	moved := person
+	moved.Address.City = "Boston"
+	moved.Tags = []string{"admin"}
+	changes, err := printer.Diff(person, moved)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(changes)
+	data, err := json.Marshal(changes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+
+	fmt.Println("\nA table of people")
+	people := []Person{person, {Name: "Bob", Age: 25, Address: Address{City: "Boston"}}}
+	if err := printer.FprintTable(os.Stdout, people, printer.Options{}); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("\nThe JSON Schema of Person")
+	schema, err := printer.JSONSchema(Person{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(schema))
+
+	fmt.Println("\nPerson as a Go literal")
+	literal, err := printer.GoLiteral(person, printer.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(literal)
 }
@@ -0,0 +1,526 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind says how a value differs between the two sides of a Diff.
+type ChangeKind string
+
+const (
+	Changed ChangeKind = "changed"
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+)
+
+// Change is one difference found by Diff. Path locates the value, as in
+// Address.City, Tags[2] or Scores["a"]; it is empty for the values passed to
+// Diff themselves. Old is unset for added values and New for removed ones.
+type Change struct {
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+	Old  any        `json:"old,omitempty"`
+	New  any        `json:"new,omitempty"`
+}
+
+// Changes is the result of Diff. It encodes to JSON as a list of Change
+// objects, and String renders it as a text diff.
+type Changes []Change
+
+// rawValue is a value already rendered as text: a redacted field, or one
+// that reflection cannot hand out, such as an unexported field.
+type rawValue string
+
+// Diff reports how b differs from a, honouring print tags: skipped fields
+// are not compared, renamed fields use their printed name in paths and
+// redacted fields are reported as changed without their values.
+func Diff(a, b any) (Changes, error) {
+	return Options{}.Diff(a, b)
+}
+
+// Diff is like the package-level Diff; HideUnexported leaves unexported
+// fields out of the comparison.
+func (o Options) Diff(a, b any) (Changes, error) {
+	d := &differ{opts: o, seen: make(map[[2]visit]bool)}
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.changes, nil
+}
+
+// String renders the changes one per line, prefixed with ~ (changed),
+// + (added) or - (removed).
+func (c Changes) String() string {
+	var sb strings.Builder
+	for _, ch := range c {
+		path := ch.Path
+		if path == "" {
+			path = "."
+		}
+		switch ch.Kind {
+		case Changed:
+			fmt.Fprintf(&sb, "~ %s: %s -> %s\n", path, formatValue(ch.Old), formatValue(ch.New))
+		case Added:
+			fmt.Fprintf(&sb, "+ %s: %s\n", path, formatValue(ch.New))
+		case Removed:
+			fmt.Fprintf(&sb, "- %s: %s\n", path, formatValue(ch.Old))
+		}
+	}
+	return sb.String()
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case rawValue:
+		return string(v)
+	case string:
+		return strconv.Quote(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		if rv.IsNil() {
+			return "nil"
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// differ holds the state of one Diff call.
+type differ struct {
+	opts    Options
+	seen    map[[2]visit]bool
+	changes Changes
+	err     error
+}
+
+func (d *differ) add(path string, kind ChangeKind, a, b reflect.Value) {
+	before, after := d.export(a), d.export(b)
+	if d.err != nil {
+		return
+	}
+	d.changes = append(d.changes, Change{Path: path, Kind: kind, Old: before, New: after})
+}
+
+// export copies v with an exporter, keeping its error.
+func (d *differ) export(v reflect.Value) any {
+	e := &exporter{opts: d.opts, seen: make(map[visit]bool)}
+	out := e.value(v)
+	if e.err != nil && d.err == nil {
+		d.err = e.err
+	}
+	return out
+}
+
+// exporter copies a value for a Change. Structs, maps, slices and pointers
+// are rebuilt with print tags applied, so skipped and redacted fields reach
+// neither the Change nor its text or JSON form. Values with a String or
+// Error method, such as time.Time, become the text it returns, as Print
+// shows them, unless NoStringers is set.
+type exporter struct {
+	opts Options
+	seen map[visit]bool
+	err  error
+}
+
+func (e *exporter) value(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	w := &walker{opts: e.opts}
+	if s, ok := w.stringer(v); ok {
+		if w.err != nil {
+			e.err = w.err
+		}
+		return rawValue(s)
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			if v.CanInterface() {
+				return v.Interface()
+			}
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return e.value(v.Elem())
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		key := visitOf(v)
+		if e.seen[key] {
+			return rawValue("<cycle " + v.Type().String() + ">")
+		}
+		e.seen[key] = true
+		defer delete(e.seen, key)
+		switch v.Kind() {
+		case reflect.Pointer:
+			return pointerValue{e.value(v.Elem())}
+		case reflect.Map:
+			return e.mapEntries(v)
+		}
+		return e.elements(v)
+	case reflect.Array:
+		return e.elements(v)
+	case reflect.Struct:
+		fields, err := e.opts.fields(v.Type())
+		if err != nil {
+			e.err = err
+			return nil
+		}
+		out := make(structValue, 0, len(fields))
+		for _, f := range fields {
+			var value any = rawValue(Redacted)
+			if !f.redact {
+				value = e.value(v.Field(f.index))
+			}
+			out = append(out, entry{f.name, value})
+		}
+		return out
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+	}
+	// Complex numbers, channels, functions and values reflection cannot
+	// hand out are kept as text, which JSON can always encode.
+	return rawValue(fmt.Sprint(v))
+}
+
+func (e *exporter) elements(v reflect.Value) listValue {
+	out := make(listValue, v.Len())
+	for i := range out {
+		out[i] = e.value(v.Index(i))
+	}
+	return out
+}
+
+func (e *exporter) mapEntries(v reflect.Value) mapValue {
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareKeys)
+	out := make(mapValue, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, entry{fmt.Sprint(e.value(k)), e.value(v.MapIndex(k))})
+	}
+	return out
+}
+
+// entry is a field of a structValue or an entry of a mapValue.
+type entry struct {
+	name  string
+	value any
+}
+
+// structValue, mapValue, listValue and pointerValue are the copies made by
+// exporter. They print like %+v and encode to JSON like the values they
+// copy, with struct fields in declaration order.
+type (
+	structValue  []entry
+	mapValue     []entry
+	listValue    []any
+	pointerValue struct{ elem any }
+)
+
+func (s structValue) String() string { return "{" + joinEntries(s) + "}" }
+func (m mapValue) String() string    { return "map[" + joinEntries(m) + "]" }
+func (p pointerValue) String() string {
+	return "&" + fmt.Sprint(p.elem)
+}
+
+func (l listValue) String() string {
+	parts := make([]string, len(l))
+	for i, v := range l {
+		parts[i] = fmt.Sprint(v)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func joinEntries(entries []entry) string {
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = e.name + ":" + fmt.Sprint(e.value)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (s structValue) MarshalJSON() ([]byte, error) { return marshalEntries(s) }
+func (m mapValue) MarshalJSON() ([]byte, error)    { return marshalEntries(m) }
+func (l listValue) MarshalJSON() ([]byte, error)   { return json.Marshal([]any(l)) }
+func (p pointerValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.elem)
+}
+
+// marshalEntries encodes entries as a JSON object, keeping their order.
+func marshalEntries(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(e.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if d.err != nil {
+		return
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, Changed, a, b)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		if a.Kind() == reflect.Struct && b.Kind() == reflect.Struct {
+			d.structs(path, a, b)
+		} else {
+			d.add(path, Changed, a, b)
+		}
+		return
+	}
+	if eq, ok := equalMethod(a, b); ok {
+		if !eq {
+			d.add(path, Changed, a, b)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Changed, a, b)
+			}
+			return
+		}
+		if a.Kind() == reflect.Pointer {
+			if !d.enter(a, b) {
+				return
+			}
+			defer d.leave(a, b)
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		d.structs(path, a, b)
+	case reflect.Array:
+		d.elements(path, a, b)
+	case reflect.Slice, reflect.Map:
+		if !d.enter(a, b) {
+			return
+		}
+		defer d.leave(a, b)
+		if a.Kind() == reflect.Map {
+			d.maps(path, a, b)
+		} else {
+			d.elements(path, a, b)
+		}
+	default:
+		if !equalScalar(a, b) {
+			d.add(path, Changed, a, b)
+		}
+	}
+}
+
+// enter reports whether the pointers, maps or slices a and b still need
+// comparing and, if so, records the pair until leave. The same value on
+// both sides is equal, and the same pair twice on one path means a cycle;
+// it holds no differences the first visit did not find. Nil values are not
+// recorded.
+func (d *differ) enter(a, b reflect.Value) bool {
+	if a.IsNil() || b.IsNil() {
+		return true
+	}
+	va, vb := visitOf(a), visitOf(b)
+	if va == vb {
+		return false
+	}
+	if d.seen[[2]visit{va, vb}] {
+		return false
+	}
+	d.seen[[2]visit{va, vb}] = true
+	return true
+}
+
+func (d *differ) leave(a, b reflect.Value) {
+	if !a.IsNil() && !b.IsNil() {
+		delete(d.seen, [2]visit{visitOf(a), visitOf(b)})
+	}
+}
+
+// structs matches fields by their printed name, so structs of different
+// types can be compared too; fields only one side has are added or removed.
+func (d *differ) structs(path string, a, b reflect.Value) {
+	fa, err := d.opts.fields(a.Type())
+	if err != nil {
+		d.err = err
+		return
+	}
+	fb, err := d.opts.fields(b.Type())
+	if err != nil {
+		d.err = err
+		return
+	}
+	for _, f := range fa {
+		i := slices.IndexFunc(fb, func(g printedField) bool { return g.name == f.name })
+		p := joinField(path, f.name)
+		switch {
+		case i < 0 && f.redact:
+			d.changes = append(d.changes, Change{Path: p, Kind: Removed, Old: rawValue(Redacted)})
+		case i < 0:
+			d.add(p, Removed, a.Field(f.index), reflect.Value{})
+		case f.redact || fb[i].redact:
+			d.redacted(p, a.Field(f.index), b.Field(fb[i].index))
+		default:
+			d.diff(p, a.Field(f.index), b.Field(fb[i].index))
+		}
+	}
+	for _, g := range fb {
+		switch {
+		case slices.ContainsFunc(fa, func(f printedField) bool { return f.name == g.name }):
+		case g.redact:
+			d.changes = append(d.changes, Change{Path: joinField(path, g.name), Kind: Added, New: rawValue(Redacted)})
+		default:
+			d.add(joinField(path, g.name), Added, reflect.Value{}, b.Field(g.index))
+		}
+	}
+}
+
+// redacted reports a change to a redacted field without its values.
+func (d *differ) redacted(path string, a, b reflect.Value) {
+	inner := &differ{opts: d.opts, seen: d.seen}
+	inner.diff(path, a, b)
+	if inner.err != nil {
+		d.err = inner.err
+		return
+	}
+	if len(inner.changes) > 0 {
+		d.changes = append(d.changes, Change{Path: path, Kind: Changed, Old: rawValue(Redacted), New: rawValue(Redacted)})
+	}
+}
+
+func (d *differ) elements(path string, a, b reflect.Value) {
+	n := max(a.Len(), b.Len())
+	for i := range n {
+		p := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= a.Len():
+			d.add(p, Added, reflect.Value{}, b.Index(i))
+		case i >= b.Len():
+			d.add(p, Removed, a.Index(i), reflect.Value{})
+		default:
+			d.diff(p, a.Index(i), b.Index(i))
+		}
+	}
+}
+
+func (d *differ) maps(path string, a, b reflect.Value) {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, compareKeys)
+	for _, k := range keys {
+		p := path + "[" + d.formatKey(k) + "]"
+		va, vb := a.MapIndex(k), b.MapIndex(k)
+		switch {
+		case !va.IsValid():
+			d.add(p, Added, va, vb)
+		case !vb.IsValid():
+			d.add(p, Removed, va, vb)
+		default:
+			d.diff(p, va, vb)
+		}
+	}
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// formatKey renders a map key for a path; struct keys are exported first,
+// so their redacted fields stay hidden.
+func (d *differ) formatKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprint(d.export(k))
+}
+
+// equalMethod compares a and b with their type's Equal(T) bool method, as
+// time.Time has, when there is one.
+func equalMethod(a, b reflect.Value) (equal, ok bool) {
+	if !a.CanInterface() {
+		return false, false
+	}
+	m, found := a.Type().MethodByName("Equal")
+	if !found {
+		return false, false
+	}
+	t := m.Type
+	if t.NumIn() != 2 || t.In(1) != a.Type() || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	if a.Kind() == reflect.Pointer && (a.IsNil() || b.IsNil()) {
+		return false, false
+	}
+	return m.Func.Call([]reflect.Value{a, b})[0].Bool(), true
+}
+
+// equalScalar compares two values of the same basic kind. Reading the
+// value through its kind works for unexported fields as well.
+func equalScalar(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		return x == y || (math.IsNaN(x) && math.IsNaN(y))
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	}
+	// Channels, functions and unsafe pointers are equal only when they
+	// are the same.
+	return a.Pointer() == b.Pointer()
+}
@@ -0,0 +1,244 @@
+package printer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Account struct {
+	Owner    Person
+	Tags     []string
+	Limits   map[string]int
+	Password string `print:"redact"`
+	Opened   time.Time
+	Parent   *Account
+}
+
+func TestDiff(t *testing.T) {
+	opened := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := Account{
+		Owner:    Person{Name: "Alice", Age: 30, Address: Address{City: "Anytown"}},
+		Tags:     []string{"a", "b", "c"},
+		Limits:   map[string]int{"daily": 100, "monthly": 1000},
+		Password: "old",
+		Opened:   opened,
+	}
+	b := a
+	b.Owner.Address.City = "Springfield"
+	b.Tags = []string{"a", "x"}
+	b.Limits = map[string]int{"daily": 200, "yearly": 5000}
+	b.Password = "new"
+	b.Opened = opened.In(time.FixedZone("CET", 3600)) // same instant
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `~ Owner.Address.City: "Anytown" -> "Springfield"
+~ Tags[1]: "b" -> "x"
+- Tags[2]: "c"
+~ Limits["daily"]: 100 -> 200
+- Limits["monthly"]: 1000
++ Limits["yearly"]: 5000
+~ Password: <redacted> -> <redacted>
+`
+	if got := changes.String(); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+
+	data, err := json.Marshal(changes[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `[{"path":"Owner.Address.City","kind":"changed","old":"Anytown","new":"Springfield"},{"path":"Tags[1]","kind":"changed","old":"b","new":"x"}]`
+	if string(data) != wantJSON {
+		t.Errorf("JSON = %s, want %s", data, wantJSON)
+	}
+}
+
+func TestDiffEqualAndCycles(t *testing.T) {
+	a := &Node{Value: 1}
+	a.Next = &Node{Value: 2, Next: a}
+	b := &Node{Value: 1}
+	b.Next = &Node{Value: 2, Next: b}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("equal cyclic lists differ:\n%s", changes)
+	}
+
+	b.Next.Value = 3
+	changes, _ = Diff(a, b)
+	if got, want := changes.String(), "~ Next.Value: 2 -> 3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDiffShapes(t *testing.T) {
+	type v1 struct {
+		Name  string
+		Debug bool
+	}
+	type v2 struct {
+		Name  string
+		Port  int
+		Token string `print:"redact"`
+	}
+	tests := []struct {
+		name string
+		a, b any
+		want string
+	}{
+		{"different structs", v1{Name: "x"}, v2{Name: "x", Port: 80, Token: "t"}, "- Debug: false\n+ Port: 80\n+ Token: <redacted>\n"},
+		{"different types", 1, "1", "~ .: 1 -> \"1\"\n"},
+		{"nil pointer", &Address{}, (*Address)(nil), "~ .: &{Street: City:} -> nil\n"},
+		{"nil and empty slice", []int(nil), []int{}, ""},
+		{"added element", []int{1}, []int{1, 2}, "+ [1]: 2\n"},
+		{"interface values", []any{1, "a"}, []any{1.0, "a"}, "~ [0]: 1 -> 1\n"},
+		{"zero value", Person{}, Person{Name: "A"}, "~ Name: \"\" -> \"A\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := changes.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Unexported fields are compared unless HideUnexported is set.
+	type secret struct{ key string }
+	changes, _ := Diff(secret{"a"}, secret{"b"})
+	if got, want := changes.String(), "~ key: a -> b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	changes, _ = Options{HideUnexported: true}.Diff(secret{"a"}, secret{"b"})
+	if len(changes) != 0 {
+		t.Errorf("hidden field compared: %s", changes)
+	}
+
+	if _, err := Diff(struct {
+		A int `print:"oops"`
+	}{}, struct{}{}); err == nil {
+		t.Error("Diff with a bad tag succeeded")
+	}
+}
+
+func TestDiffRedactsNestedValues(t *testing.T) {
+	type Cred struct {
+		User     string
+		Password string `print:"redact"`
+		Note     string `print:"-"`
+	}
+	type Config struct {
+		Cfgs []Cred
+		P    *Cred
+		M    map[string]Cred
+	}
+	secret := Cred{User: "u", Password: "hunter2", Note: "hunter2"}
+	a := Config{}
+	b := Config{Cfgs: []Cred{secret}, P: &secret, M: map[string]Cred{"k": secret}}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `+ Cfgs[0]: {User:u Password:<redacted>}
+~ P: nil -> &{User:u Password:<redacted>}
++ M["k"]: {User:u Password:<redacted>}
+`
+	if got := changes.String(); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("JSON leaks a redacted value: %s", data)
+	}
+	wantJSON := `{"path":"P","kind":"changed","old":null,"new":{"User":"u","Password":"\u003credacted\u003e"}}`
+	if !strings.Contains(string(data), wantJSON) {
+		t.Errorf("JSON = %s, want it to contain %s", data, wantJSON)
+	}
+}
+
+// login is a Stringer with a redacted field.
+type login struct {
+	User string
+	Pass string `print:"redact"`
+}
+
+func (l login) String() string { return "login " + l.User }
+
+func TestDiffStringers(t *testing.T) {
+	type Outer struct {
+		Items []login
+		Seen  map[login]int
+	}
+	b := Outer{Items: []login{{"u", "hunter2"}}, Seen: map[login]int{{"v", "hunter2"}: 1}}
+
+	for _, opts := range []Options{{}, {NoStringers: true}} {
+		changes, err := opts.Diff(Outer{}, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(changes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := changes.String(); strings.Contains(text, "hunter2") || strings.Contains(string(data), "hunter2") {
+			t.Errorf("NoStringers=%v: Diff leaks a redacted value:\n%s%s", opts.NoStringers, text, data)
+		}
+	}
+
+	changes, _ := Diff(Outer{}, b)
+	want := `+ Items[0]: login u
++ Seen[login v]: 1
+`
+	if got := changes.String(); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	data, _ := json.Marshal(changes[0])
+	if wantJSON := `{"path":"Items[0]","kind":"added","new":"login u"}`; string(data) != wantJSON {
+		t.Errorf("JSON = %s, want %s", data, wantJSON)
+	}
+}
+
+func TestDiffCyclicMapsAndSlices(t *testing.T) {
+	m1 := map[string]any{"n": 1}
+	m1["self"] = m1
+	m2 := map[string]any{"n": 2}
+	m2["self"] = m2
+	changes, err := Diff(m1, m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := changes.String(), "~ [\"n\"]: 1 -> 2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	s1 := []any{1, nil}
+	s1[1] = s1
+	s2 := []any{2, nil}
+	s2[1] = s2
+	changes, _ = Diff(s1, s2)
+	if got, want := changes.String(), "~ [0]: 1 -> 2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A cyclic value that was added is printed up to the cycle.
+	changes, _ = Diff(map[string]any{}, map[string]any{"m": m1})
+	if got, want := changes.String(), "+ [\"m\"]: map[n:1 self:<cycle map[string]interface {}>]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,267 @@
+package printer
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoLiteral returns v as a Go composite literal that can be pasted into a
+// test, such as main.Person{Name: "Alice", Tags: []string{"a"}} spread over
+// several lines. Zero-valued fields are left out, and so are fields tagged
+// print:"redact", so secrets never end up in the source. Indent defaults to
+// a tab; MaxDepth and NoStringers do not apply. Values that have no literal
+// form, such as cyclic data, channels and functions, are errors.
+func GoLiteral(v any, opts Options) (string, error) {
+	if opts.Indent == "" {
+		opts.Indent = "\t"
+	}
+	l := &literal{opts: opts, seen: make(map[visit]bool)}
+	l.value(reflect.ValueOf(v), 0, true)
+	if l.err != nil {
+		return "", l.err
+	}
+	return l.sb.String(), nil
+}
+
+// literal holds the state of one GoLiteral call.
+type literal struct {
+	sb   strings.Builder
+	opts Options
+	seen map[visit]bool
+	err  error
+}
+
+func (l *literal) write(s string) {
+	l.sb.WriteString(s)
+}
+
+func (l *literal) newline(depth int) {
+	l.write("\n" + strings.Repeat(l.opts.Indent, depth))
+}
+
+func (l *literal) fail(format string, args ...any) {
+	if l.err == nil {
+		l.err = fmt.Errorf("printer: "+format, args...)
+	}
+}
+
+// value writes v. typed reports whether the literal must name its type, as
+// at the top level, in fields and in interfaces; elements, keys and values
+// of composite literals may leave it out.
+func (l *literal) value(v reflect.Value, depth int, typed bool) {
+	if l.err != nil {
+		return
+	}
+	if !v.IsValid() {
+		l.write("nil")
+		return
+	}
+	if v.Type() == timeType && v.CanInterface() {
+		l.time(v.Interface().(time.Time))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			l.write("nil")
+			return
+		}
+		if !l.enter(v) {
+			return
+		}
+		defer delete(l.seen, visitOf(v))
+		switch v.Elem().Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			l.write("&")
+			l.value(v.Elem(), depth, true)
+		default:
+			// There is no &-literal for other kinds; use a function that
+			// returns the address of a local.
+			t := v.Elem().Type().String()
+			l.write("func() *" + t + " { v := ")
+			l.value(v.Elem(), depth, true)
+			l.write("; return &v }()")
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			l.write("nil")
+			return
+		}
+		l.value(v.Elem(), depth, true)
+	case reflect.Struct:
+		l.structFields(v, depth, typed)
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			l.write("nil")
+			return
+		}
+		if !l.enter(v) {
+			return
+		}
+		defer delete(l.seen, visitOf(v))
+		if v.Kind() == reflect.Map {
+			l.mapEntries(v, depth, typed)
+		} else {
+			l.elements(v, depth, typed)
+		}
+	case reflect.Array:
+		l.elements(v, depth, typed)
+	case reflect.String:
+		l.scalar(v, strconv.Quote(v.String()), typed, reflect.String)
+	case reflect.Bool:
+		l.scalar(v, strconv.FormatBool(v.Bool()), typed, reflect.Bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		l.scalar(v, strconv.FormatInt(v.Int(), 10), typed, reflect.Int)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		l.scalar(v, strconv.FormatUint(v.Uint(), 10), typed, reflect.Invalid)
+	case reflect.Float32, reflect.Float64:
+		l.scalar(v, formatFloat(v.Float(), v.Type().Bits()), typed, reflect.Float64)
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		s := "complex(" + formatFloat(real(c), 64) + ", " + formatFloat(imag(c), 64) + ")"
+		l.scalar(v, s, typed, reflect.Complex128)
+	default:
+		if v.IsNil() {
+			l.write("nil")
+			return
+		}
+		l.fail("a %s has no Go literal", v.Type())
+	}
+}
+
+// scalar writes the constant s, converted to v's type when typed is set and
+// the constant alone would have another default type.
+func (l *literal) scalar(v reflect.Value, s string, typed bool, def reflect.Kind) {
+	t := v.Type()
+	if !typed || (t.Kind() == def && t.PkgPath() == "" && t.Name() == def.String()) {
+		l.write(s)
+		return
+	}
+	l.write(t.String() + "(" + s + ")")
+}
+
+func formatFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0" // keep it a floating-point constant
+	}
+	return s
+}
+
+func (l *literal) time(t time.Time) {
+	loc := "time.FixedZone(" + strconv.Quote(t.Location().String()) + ", " + strconv.Itoa(offset(t)) + ")"
+	switch t.Location() {
+	case time.UTC:
+		loc = "time.UTC"
+	case time.Local:
+		loc = "time.Local"
+	}
+	l.write(fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, %s)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc))
+}
+
+func offset(t time.Time) int {
+	_, off := t.Zone()
+	return off
+}
+
+// enter marks a pointer, map or slice as being written and fails on cycles,
+// which a literal cannot express.
+func (l *literal) enter(v reflect.Value) bool {
+	key := visitOf(v)
+	if l.seen[key] {
+		l.fail("a cyclic %s has no Go literal", v.Type())
+		return false
+	}
+	l.seen[key] = true
+	return true
+}
+
+// open writes the type of a composite literal, unless it can be elided,
+// and its opening brace.
+func (l *literal) open(t reflect.Type, typed bool) {
+	if typed {
+		l.write(t.String())
+	}
+	l.write("{")
+}
+
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func (l *literal) structFields(v reflect.Value, depth int, typed bool) {
+	fields, err := l.opts.fields(v.Type())
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.open(v.Type(), typed)
+	n := 0
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.redact || fv.IsZero() {
+			continue
+		}
+		l.newline(depth + 1)
+		l.write(v.Type().Field(f.index).Name + ": ")
+		// Field values must name composite types; constants only need a
+		// conversion in an interface field.
+		l.value(fv, depth+1, fv.Kind() == reflect.Interface || isComposite(fv.Type()))
+		l.write(",")
+		n++
+	}
+	l.close(n, depth)
+}
+
+func (l *literal) elements(v reflect.Value, depth int, typed bool) {
+	l.open(v.Type(), typed)
+	// Elements of a composite literal may leave out their type.
+	typed = v.Type().Elem().Kind() == reflect.Interface
+	for i := range v.Len() {
+		l.newline(depth + 1)
+		l.value(v.Index(i), depth+1, typed)
+		l.write(",")
+	}
+	l.close(v.Len(), depth)
+}
+
+func (l *literal) mapEntries(v reflect.Value, depth int, typed bool) {
+	l.open(v.Type(), typed)
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareKeys)
+	t := v.Type()
+	for _, k := range keys {
+		l.newline(depth + 1)
+		l.value(k, depth+1, t.Key().Kind() == reflect.Interface)
+		l.write(": ")
+		l.value(v.MapIndex(k), depth+1, t.Elem().Kind() == reflect.Interface)
+		l.write(",")
+	}
+	l.close(len(keys), depth)
+}
+
+func (l *literal) close(n, depth int) {
+	if n > 0 {
+		l.newline(depth)
+	}
+	l.write("}")
+}
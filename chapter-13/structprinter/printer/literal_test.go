@@ -0,0 +1,81 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type Level int
+
+func TestGoLiteral(t *testing.T) {
+	n := 7
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"int", 42, "42"},
+		{"named", Level(2), "printer.Level(2)"},
+		{"nil", nil, "nil"},
+		{"pointer to int", &n, "func() *int { v := 7; return &v }()"},
+		{"time", time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC), "time.Date(2024, time.February, 29, 12, 30, 0, 0, time.UTC)"},
+		{
+			"struct",
+			Team{
+				Name:    "core",
+				Lead:    &Person{Name: "Alice", Age: 30},
+				Members: []Person{{Name: "Bob"}},
+				Scores:  map[string]int{"b": 2, "a": 1},
+				Extra:   []any{1, 2.0, "x", Level(3), Address{City: "Anytown"}},
+			},
+			`printer.Team{
+	Name: "core",
+	Lead: &printer.Person{
+		Name: "Alice",
+		Age: 30,
+	},
+	Members: []printer.Person{
+		{
+			Name: "Bob",
+		},
+	},
+	Scores: map[string]int{
+		"a": 1,
+		"b": 2,
+	},
+	Extra: []interface {}{
+		1,
+		2.0,
+		"x",
+		printer.Level(3),
+		printer.Address{
+			City: "Anytown",
+		},
+	},
+}`,
+		},
+		{"redacted", DBConfig{Host: "db", Password: "s3cret"}, "printer.DBConfig{\n\tHost: \"db\",\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GoLiteral(tt.v, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoLiteralErrors(t *testing.T) {
+	a := &Node{Value: 1}
+	a.Next = a
+	for _, v := range []any{a, make(chan int), func() {}} {
+		if _, err := GoLiteral(v, Options{}); err == nil || !strings.Contains(err.Error(), "has no Go literal") {
+			t.Errorf("GoLiteral(%T) error = %v", v, err)
+		}
+	}
+}
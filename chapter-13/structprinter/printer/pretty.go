@@ -0,0 +1,314 @@
+package printer
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultIndent is used when Options.Indent is empty.
+const DefaultIndent = "  "
+
+// Options controls how Sprint and Fprint lay out a value.
+type Options struct {
+	// Indent is written once per nesting level. Empty means DefaultIndent.
+	Indent string
+	// MaxDepth limits how deep structs, slices, arrays, maps and pointers
+	// are walked; deeper values are shown as "Type{...}". Zero means no
+	// limit.
+	MaxDepth int
+	// NoStringers prints the fields of values that implement fmt.Stringer
+	// or error instead of calling their String or Error method.
+	NoStringers bool
+	// HideUnexported leaves out unexported struct fields.
+	HideUnexported bool
+}
+
+var (
+	stringerType = reflect.TypeFor[fmt.Stringer]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+// Sprint returns the pretty-printed form of v.
+func Sprint(v any, opts Options) (string, error) {
+	var sb strings.Builder
+	if err := Fprint(&sb, v, opts); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Fprint writes v to w, one field, element or map entry per line, indented
+// by nesting level and followed by a newline. Nil pointers, maps, slices and
+// interfaces print as nil, and a pointer, map or slice that refers back to a
+// value being printed prints as <cycle Type> instead of recursing forever.
+func Fprint(w io.Writer, v any, opts Options) error {
+	if opts.MaxDepth < 0 {
+		return fmt.Errorf("printer: negative MaxDepth %d", opts.MaxDepth)
+	}
+	if opts.Indent == "" {
+		opts.Indent = DefaultIndent
+	}
+	p := &walker{w: w, opts: opts, seen: make(map[visit]bool)}
+	p.value(reflect.ValueOf(v), 0)
+	p.write("\n")
+	return p.err
+}
+
+// visit identifies a pointer, map or slice on the path being printed.
+type visit struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// walker holds the state of one Fprint call. The first error, from the
+// writer or from a String method, stops all further output.
+type walker struct {
+	w    io.Writer
+	opts Options
+	seen map[visit]bool
+	err  error
+}
+
+func (p *walker) write(s string) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = io.WriteString(p.w, s)
+}
+
+func (p *walker) newline(depth int) {
+	p.write("\n" + strings.Repeat(p.opts.Indent, depth))
+}
+
+func (p *walker) value(v reflect.Value, depth int) {
+	if p.err != nil {
+		return
+	}
+	if !v.IsValid() {
+		p.write("nil")
+		return
+	}
+	if s, ok := p.stringer(v); ok {
+		p.write(s)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			p.write("nil")
+			return
+		}
+		if !p.enter(v, depth) {
+			return
+		}
+		defer p.leave(v)
+		p.write("&")
+		p.value(v.Elem(), depth)
+	case reflect.Interface:
+		if v.IsNil() {
+			p.write("nil")
+			return
+		}
+		p.value(v.Elem(), depth)
+	case reflect.Struct:
+		p.structFields(v, depth)
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			p.write("nil")
+			return
+		}
+		if !p.enter(v, depth) {
+			return
+		}
+		defer p.leave(v)
+		if v.Kind() == reflect.Map {
+			p.mapEntries(v, depth)
+		} else {
+			p.elements(v, depth)
+		}
+	case reflect.Array:
+		p.elements(v, depth)
+	case reflect.String:
+		p.write(strconv.Quote(v.String()))
+	case reflect.Bool:
+		p.write(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.write(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.write(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		p.write(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		p.write(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	default:
+		// Channels, functions and unsafe pointers have no printable contents.
+		if v.IsNil() {
+			p.write("nil")
+		} else {
+			p.write("(" + v.Type().String() + ")")
+		}
+	}
+}
+
+// enter marks a pointer, map or slice as being printed. It reports false,
+// after printing a marker, if the value is already on the path (a cycle) or
+// lies beyond MaxDepth.
+func (p *walker) enter(v reflect.Value, depth int) bool {
+	key := visitOf(v)
+	if p.seen[key] {
+		p.write("<cycle " + v.Type().String() + ">")
+		return false
+	}
+	if v.Kind() != reflect.Pointer && p.tooDeep(v, depth) {
+		return false
+	}
+	p.seen[key] = true
+	return true
+}
+
+func (p *walker) leave(v reflect.Value) {
+	delete(p.seen, visitOf(v))
+}
+
+func visitOf(v reflect.Value) visit {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	return key
+}
+
+// tooDeep prints "Type{...}" and reports true when a non-empty composite
+// value at depth would exceed MaxDepth.
+func (p *walker) tooDeep(v reflect.Value, depth int) bool {
+	if p.opts.MaxDepth == 0 || depth < p.opts.MaxDepth {
+		return false
+	}
+	if (v.Kind() == reflect.Struct && v.NumField() == 0) || (v.Kind() != reflect.Struct && v.Len() == 0) {
+		return false
+	}
+	p.write(v.Type().String() + "{...}")
+	return true
+}
+
+func (p *walker) structFields(v reflect.Value, depth int) {
+	if p.tooDeep(v, depth) {
+		return
+	}
+	fields, err := p.opts.fields(v.Type())
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.write(v.Type().String() + "{")
+	for _, f := range fields {
+		p.newline(depth + 1)
+		p.write(f.name + ": ")
+		if f.redact {
+			p.write(Redacted)
+		} else {
+			p.value(v.Field(f.index), depth+1)
+		}
+	}
+	p.close(len(fields), depth)
+}
+
+func (p *walker) elements(v reflect.Value, depth int) {
+	if p.tooDeep(v, depth) {
+		return
+	}
+	p.write(v.Type().String() + "{")
+	for i := range v.Len() {
+		p.newline(depth + 1)
+		p.value(v.Index(i), depth+1)
+	}
+	p.close(v.Len(), depth)
+}
+
+func (p *walker) mapEntries(v reflect.Value, depth int) {
+	p.write(v.Type().String() + "{")
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareKeys)
+	for _, k := range keys {
+		p.newline(depth + 1)
+		p.value(k, depth+1)
+		p.write(": ")
+		p.value(v.MapIndex(k), depth+1)
+	}
+	p.close(len(keys), depth)
+}
+
+// close ends a struct, slice, array or map with n entries.
+func (p *walker) close(n, depth int) {
+	if n > 0 {
+		p.newline(depth)
+	}
+	p.write("}")
+}
+
+// stringer returns the result of v's String or Error method, if it has one
+// that can be called. A method that panics becomes the walker's error.
+func (p *walker) stringer(v reflect.Value) (s string, ok bool) {
+	if p.opts.NoStringers || !v.CanInterface() {
+		return "", false
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && !implementsStringer(v.Type()) {
+		v = v.Addr()
+	}
+	if !implementsStringer(v.Type()) {
+		return "", false
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			p.err = fmt.Errorf("printer: String method of %s panicked: %v", v.Type(), r)
+			s, ok = "", true
+		}
+	}()
+	switch x := v.Interface().(type) {
+	case error:
+		return x.Error(), true
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	return "", false
+}
+
+func implementsStringer(t reflect.Type) bool {
+	return t.Implements(errorType) || t.Implements(stringerType)
+}
+
+// compareKeys orders map keys: numbers numerically, strings and booleans
+// naturally, and anything else by its fmt representation.
+func compareKeys(a, b reflect.Value) int {
+	if a.Kind() == reflect.Interface {
+		a, b = a.Elem(), b.Elem()
+		if a.IsValid() && b.IsValid() && a.Kind() != b.Kind() {
+			return cmp.Compare(a.Kind(), b.Kind())
+		}
+	}
+	if a.IsValid() && b.IsValid() {
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(a.Int(), b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(a.Uint(), b.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(a.Float(), b.Float())
+		case reflect.String:
+			return cmp.Compare(a.String(), b.String())
+		case reflect.Bool:
+			return cmp.Compare(strconv.FormatBool(a.Bool()), strconv.FormatBool(b.Bool()))
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
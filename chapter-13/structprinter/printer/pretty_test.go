@@ -0,0 +1,195 @@
+package printer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+type Team struct {
+	Name    string
+	Lead    *Person
+	Members []Person
+	Scores  map[string]int
+	Extra   any
+	Labels  [2]string
+}
+
+type panicky struct{}
+
+func (panicky) String() string { panic("boom") }
+
+func TestSprint(t *testing.T) {
+	alice := &Person{Name: "Alice", Age: 30, Address: Address{Street: "123 Main Street", City: "Anytown"}}
+	tests := []struct {
+		name string
+		v    any
+		opts Options
+		want string
+	}{
+		{"nil", nil, Options{}, "nil\n"},
+		{"string", "hi\n", Options{}, "\"hi\\n\"\n"},
+		{"float", 1.5, Options{}, "1.5\n"},
+		{"nil pointer", (*Person)(nil), Options{}, "nil\n"},
+		{"empty slice", []int{}, Options{}, "[]int{}\n"},
+		{"nil map", map[string]int(nil), Options{}, "nil\n"},
+		{"stringer", 90 * time.Second, Options{}, "1m30s\n"},
+		{"stringer off", 90 * time.Second, Options{NoStringers: true}, "90000000000\n"},
+		{"error", errors.New("bad"), Options{}, "bad\n"},
+		{
+			"struct",
+			Person{Name: "Alice", Age: 30, Address: Address{Street: "123 Main Street", City: "Anytown"}},
+			Options{},
+			`printer.Person{
+  Name: "Alice"
+  Age: 30
+  Address: printer.Address{
+    Street: "123 Main Street"
+    City: "Anytown"
+  }
+}
+`,
+		},
+		{
+			"every kind",
+			Team{
+				Name:    "core",
+				Lead:    alice,
+				Members: []Person{{Name: "Bob"}},
+				Scores:  map[string]int{"b": 2, "a": 1},
+				Extra:   []any{3, nil},
+			},
+			Options{Indent: "\t"},
+			`printer.Team{
+	Name: "core"
+	Lead: &printer.Person{
+		Name: "Alice"
+		Age: 30
+		Address: printer.Address{
+			Street: "123 Main Street"
+			City: "Anytown"
+		}
+	}
+	Members: []printer.Person{
+		printer.Person{
+			Name: "Bob"
+			Age: 0
+			Address: printer.Address{
+				Street: ""
+				City: ""
+			}
+		}
+	}
+	Scores: map[string]int{
+		"a": 1
+		"b": 2
+	}
+	Extra: []interface {}{
+		3
+		nil
+	}
+	Labels: [2]string{
+		""
+		""
+	}
+}
+`,
+		},
+		{
+			"max depth",
+			Team{Name: "core", Lead: alice, Scores: map[string]int{"a": 1}},
+			Options{MaxDepth: 1},
+			`printer.Team{
+  Name: "core"
+  Lead: &printer.Person{...}
+  Members: nil
+  Scores: map[string]int{...}
+  Extra: nil
+  Labels: [2]string{...}
+}
+`,
+		},
+		{
+			"sorted int keys",
+			map[int]bool{10: true, 9: false},
+			Options{},
+			"map[int]bool{\n  9: false\n  10: true\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sprint(tt.v, tt.opts)
+			if err != nil {
+				t.Fatalf("Sprint: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sprint() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSprintCycles(t *testing.T) {
+	a := &Node{Value: 1}
+	a.Next = &Node{Value: 2, Next: a}
+	got, err := Sprint(a, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `&printer.Node{
+  Value: 1
+  Next: &printer.Node{
+    Value: 2
+    Next: <cycle *printer.Node>
+  }
+}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	m := map[string]any{}
+	m["self"] = m
+	got, err = Sprint(m, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "map[string]interface {}{\n  \"self\": <cycle map[string]interface {}>\n}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A value shared by two fields is not a cycle.
+	shared := &Address{City: "Anytown"}
+	got, _ = Sprint([]*Address{shared, shared}, Options{})
+	if strings.Contains(got, "cycle") {
+		t.Errorf("shared pointer reported as a cycle:\n%s", got)
+	}
+}
+
+type failingWriter struct{ n int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestFprintErrors(t *testing.T) {
+	if err := Fprint(&failingWriter{n: 2}, Person{Name: "Alice"}, Options{}); err == nil || err.Error() != "disk full" {
+		t.Errorf("Fprint to failing writer = %v, want disk full", err)
+	}
+	if _, err := Sprint(1, Options{MaxDepth: -1}); err == nil {
+		t.Error("Sprint with negative MaxDepth succeeded")
+	}
+	if _, err := Sprint(panicky{}, Options{}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Sprint with panicking String = %v, want error mentioning boom", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 )
 
+// PrintStructFields prints the fields of a struct to standard output, one
+// line per field. Sprint and Fprint cover every kind and report errors.
 func PrintStructFields(data interface{}) {
 	val := reflect.ValueOf(data)
 	if val.Kind() == reflect.Ptr {
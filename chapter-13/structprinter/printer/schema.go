@@ -0,0 +1,267 @@
+package printer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON Schema dialect JSONSchema produces.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schema is one JSON Schema object; only the keywords JSONSchema needs.
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"` // a name or a list of names
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	AnyOf                []*schema          `json:"anyOf,omitempty"`
+	Properties           properties         `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*schema `json:"$defs,omitempty"`
+}
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+// properties keeps the fields in struct order when encoded.
+type properties []property
+
+func (ps properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range ps {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(p.name)
+		value, err := json.Marshal(p.schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// JSONSchema returns an indented JSON Schema describing how encoding/json
+// encodes values of v's type. Only the type matters, so v may be a nil
+// pointer such as (*Config)(nil). Field names and omitempty come from json
+// tags; fields without omitempty or omitzero, other than pointers, are
+// required. Pointers, slices and maps may be null, as their nil values
+// encode to null. Recursive types are described through $defs.
+func JSONSchema(v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("printer: JSONSchema needs a typed value, got nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	b := &schemaBuilder{root: t, building: make(map[reflect.Type]bool), recursive: make(map[reflect.Type]bool), defs: make(map[string]*schema)}
+	s, err := b.schema(t)
+	if err != nil {
+		return nil, fmt.Errorf("printer: %w", err)
+	}
+	s.Schema = schemaDraft
+	s.Title = t.Name()
+	if len(b.defs) > 0 {
+		s.Defs = b.defs
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// schemaBuilder holds the state of one JSONSchema call. Structs are
+// described inline; one that contains itself is moved to $defs and
+// referenced.
+type schemaBuilder struct {
+	root      reflect.Type
+	building  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	defs      map[string]*schema
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) (*schema, error) {
+	if t.Kind() == reflect.Pointer {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		s, err := b.schema(t)
+		if err != nil {
+			return nil, err
+		}
+		return orNull(s), nil
+	}
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}, nil
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &schema{}, nil // encodes itself; anything goes
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0
+		return &schema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return orNull(&schema{Type: "string", ContentEncoding: "base64"}), nil
+		}
+		items, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s := &schema{Type: "array", Items: items}
+		if t.Kind() == reflect.Slice {
+			return orNull(s), nil
+		}
+		return s, nil
+	case reflect.Map:
+		values, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return orNull(&schema{Type: "object", AdditionalProperties: values}), nil
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	return nil, fmt.Errorf("%s cannot be encoded as JSON", t)
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) (*schema, error) {
+	if b.building[t] {
+		b.recursive[t] = true
+		return &schema{Ref: b.ref(t)}, nil
+	}
+	b.building[t] = true
+	defer delete(b.building, t)
+
+	s := &schema{Type: "object", AdditionalProperties: false}
+	if err := b.fields(s, t, map[reflect.Type]bool{t: true}); err != nil {
+		return nil, err
+	}
+	if b.recursive[t] && t != b.root {
+		b.defs[t.Name()] = s
+		return &schema{Ref: b.ref(t)}, nil
+	}
+	return s, nil
+}
+
+func (b *schemaBuilder) ref(t reflect.Type) string {
+	if t == b.root {
+		return "#"
+	}
+	return "#/$defs/" + t.Name()
+}
+
+// fields adds the properties of struct type t to s. Embedded structs
+// without a json name are flattened, as encoding/json does. embedded holds
+// the structs being flattened; one embedded again inside itself is
+// skipped, since its fields are already there at a shallower depth, which
+// encoding/json gives precedence.
+func (b *schemaBuilder) fields(s *schema, t reflect.Type, embedded map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if embedded[ft] {
+				continue
+			}
+			embedded[ft] = true
+			err := b.fields(s, ft, embedded)
+			delete(embedded, ft)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ps, err := b.schema(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %w", t, f.Name, err)
+		}
+		if hasOption(opts, "string") {
+			ps = &schema{Type: "string"}
+			if f.Type.Kind() == reflect.Pointer {
+				ps = orNull(ps)
+			}
+		}
+		s.Properties = append(s.Properties, property{name: name, schema: ps})
+		optional := f.Type.Kind() == reflect.Pointer || hasOption(opts, "omitempty") || hasOption(opts, "omitzero")
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// orNull returns a schema that also allows null: a list of types when s
+// names one, and an anyOf otherwise. A schema that allows anything is
+// returned as is.
+func orNull(s *schema) *schema {
+	switch t := s.Type.(type) {
+	case string:
+		s.Type = []string{t, "null"}
+		return s
+	case []string:
+		return s
+	}
+	if s.Ref == "" && s.AnyOf == nil {
+		return s
+	}
+	return &schema{AnyOf: []*schema{s, {Type: "null"}}}
+}
+
+func hasOption(opts, name string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package printer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Base struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type Document struct {
+	Base
+	Title    string            `json:"title"`
+	Body     []byte            `json:"body,omitempty"`
+	Size     uint              `json:"size,string"`
+	Meta     map[string]any    `json:"meta,omitempty"`
+	Parent   *Document         `json:"parent"`
+	Children []Section         `json:"children"`
+	Internal string            `json:"-"`
+	labels   map[string]string // unexported, not encoded
+}
+
+type Section struct {
+	Heading string    `json:"heading"`
+	Sub     []Section `json:"sub,omitempty"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	got, err := JSONSchema((*Document)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Document",
+  "type": "object",
+  "properties": {
+    "id": {
+      "type": "integer"
+    },
+    "created": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "title": {
+      "type": "string"
+    },
+    "body": {
+      "type": [
+        "string",
+        "null"
+      ],
+      "contentEncoding": "base64"
+    },
+    "size": {
+      "type": "string"
+    },
+    "meta": {
+      "type": [
+        "object",
+        "null"
+      ],
+      "additionalProperties": {}
+    },
+    "parent": {
+      "anyOf": [
+        {
+          "$ref": "#"
+        },
+        {
+          "type": "null"
+        }
+      ]
+    },
+    "children": {
+      "type": [
+        "array",
+        "null"
+      ],
+      "items": {
+        "$ref": "#/$defs/Section"
+      }
+    }
+  },
+  "required": [
+    "id",
+    "created",
+    "title",
+    "size",
+    "children"
+  ],
+  "additionalProperties": false,
+  "$defs": {
+    "Section": {
+      "type": "object",
+      "properties": {
+        "heading": {
+          "type": "string"
+        },
+        "sub": {
+          "type": [
+            "array",
+            "null"
+          ],
+          "items": {
+            "$ref": "#/$defs/Section"
+          }
+        }
+      },
+      "required": [
+        "heading"
+      ],
+      "additionalProperties": false
+    }
+  }
+}`
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if !json.Valid(got) {
+		t.Error("schema is not valid JSON")
+	}
+}
+
+type Ring struct {
+	*Link
+	Name string `json:"name"`
+}
+
+type Link struct {
+	*Ring
+	Next string `json:"next"`
+}
+
+func TestJSONSchemaEmbeddedCycle(t *testing.T) {
+	got, err := JSONSchema(Ring{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(got, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Properties) != 2 || s.Properties["name"] == nil || s.Properties["next"] == nil {
+		t.Errorf("expected the properties name and next, got\n%s", got)
+	}
+}
+
+func TestJSONSchemaErrors(t *testing.T) {
+	if _, err := JSONSchema(nil); err == nil {
+		t.Error("JSONSchema(nil) succeeded")
+	}
+	_, err := JSONSchema(struct{ C chan int }{})
+	if err == nil || !strings.Contains(err.Error(), "C: chan int cannot be encoded as JSON") {
+		t.Errorf("error = %v", err)
+	}
+}
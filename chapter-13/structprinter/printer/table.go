@@ -0,0 +1,127 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// FprintTable writes rows, a slice or array of structs or struct pointers,
+// as an aligned table with one column per printed field. Print tags and
+// HideUnexported apply as in Fprint; numbers are right-aligned.
+func FprintTable(w io.Writer, rows any, opts Options) error {
+	v := reflect.ValueOf(rows)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("printer: table needs a slice of structs, got %T", rows)
+	}
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("printer: table needs a slice of structs, got %T", rows)
+	}
+	fields, err := opts.fields(elem)
+	if err != nil {
+		return err
+	}
+
+	table := make([][]string, 0, v.Len()+2)
+	header := make([]string, len(fields))
+	right := make([]bool, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+		right[i] = isNumber(elem.Field(f.index).Type)
+	}
+	table = append(table, header, nil)
+	e := &exporter{opts: opts, seen: make(map[visit]bool)}
+	for i := range v.Len() {
+		row := v.Index(i)
+		if row.Kind() == reflect.Pointer {
+			row = row.Elem() // a nil row is invalid and gets empty cells
+		}
+		cells := make([]string, len(fields))
+		for j, f := range fields {
+			switch {
+			case !row.IsValid():
+			case f.redact:
+				cells[j] = Redacted
+			default:
+				cells[j] = e.cellText(row.Field(f.index))
+			}
+		}
+		table = append(table, cells)
+	}
+	if e.err != nil {
+		return e.err
+	}
+
+	widths := make([]int, len(fields))
+	for _, cells := range table {
+		for j, c := range cells {
+			widths[j] = max(widths[j], utf8.RuneCountInString(c))
+		}
+	}
+	rule := make([]string, len(fields))
+	for j, n := range widths {
+		rule[j] = strings.Repeat("-", n)
+	}
+	table[1] = rule
+
+	var sb strings.Builder
+	for _, cells := range table {
+		var line strings.Builder
+		for j, c := range cells {
+			if j > 0 {
+				line.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(c))
+			if right[j] {
+				line.WriteString(pad + c)
+			} else {
+				line.WriteString(c + pad)
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// SprintTable returns the table FprintTable would write.
+func SprintTable(rows any, opts Options) (string, error) {
+	var sb strings.Builder
+	if err := FprintTable(&sb, rows, opts); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// cellText renders a field on one line, with print tags applied to the
+// fields of nested structs as in a Diff; nil pointers and interfaces are
+// left empty and line breaks are escaped.
+func (e *exporter) cellText(v reflect.Value) string {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return ""
+	}
+	s := fmt.Sprint(e.value(v))
+	return strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s)
+}
+
+func isNumber(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSprintTable(t *testing.T) {
+	rows := []*DBConfig{
+		{Host: "db.internal", Port: 5432, User: "admin", Password: "s3cret", dsn: "x"},
+		nil,
+		{Host: "cache", Port: 6379},
+	}
+	got, err := SprintTable(rows, Options{HideUnexported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Host         port  user        Password
+-----------  ----  ----------  ----------
+db.internal  5432  <redacted>  <redacted>
+
+cache        6379  <redacted>  <redacted>
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = SprintTable([]Address{{Street: "Line 1\nLine 2", City: "Zürich"}}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `Street          City
+--------------  ------
+Line 1\nLine 2  Zürich
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	for _, bad := range []any{Person{}, []int{1}, nil} {
+		if _, err := SprintTable(bad, Options{}); err == nil || !strings.Contains(err.Error(), "slice of structs") {
+			t.Errorf("SprintTable(%T) error = %v", bad, err)
+		}
+	}
+}
+
+func TestSprintTableNestedTags(t *testing.T) {
+	type Plain struct {
+		User string `print:"name=user"`
+		Pass string `print:"redact"`
+		Note string `print:"-"`
+	}
+	type Row struct {
+		Name   string
+		Login  Plain
+		Logins []*Plain
+	}
+	rows := []Row{{"a", Plain{"bob", "hunter2", "x"}, []*Plain{{User: "eve", Pass: "hunter2"}}}}
+	got, err := SprintTable(rows, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Name  Login                       Logins
+----  --------------------------  -----------------------------
+a     {user:bob Pass:<redacted>}  [&{user:eve Pass:<redacted>}]
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
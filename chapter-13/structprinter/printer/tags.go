@@ -0,0 +1,72 @@
+package printer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of a field tagged print:"redact".
+const Redacted = "<redacted>"
+
+// fieldTag is a parsed `print:"..."` struct tag. The tag is a comma-separated
+// list of "-" (skip the field), "name=..." (print it under another name) and
+// "redact" (hide its value).
+type fieldTag struct {
+	skip   bool
+	name   string
+	redact bool
+}
+
+// parseTag reads the print tag of field f of struct type t.
+func parseTag(t reflect.Type, f reflect.StructField) (fieldTag, error) {
+	tag := fieldTag{name: f.Name}
+	value, ok := f.Tag.Lookup("print")
+	if !ok || value == "" {
+		return tag, nil
+	}
+	if value == "-" {
+		tag.skip = true
+		return tag, nil
+	}
+	for _, opt := range strings.Split(value, ",") {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "redact":
+			tag.redact = true
+		case strings.HasPrefix(opt, "name="):
+			tag.name = strings.TrimPrefix(opt, "name=")
+			if tag.name == "" {
+				return tag, fmt.Errorf("printer: field %s.%s: empty name in print tag", t, f.Name)
+			}
+		default:
+			return tag, fmt.Errorf("printer: field %s.%s: unknown print tag option %q", t, f.Name, opt)
+		}
+	}
+	return tag, nil
+}
+
+// printedField is a struct field that Options allow to be printed.
+type printedField struct {
+	index int
+	fieldTag
+}
+
+// fields returns the fields of struct type t to print, in order.
+func (o Options) fields(t reflect.Type) ([]printedField, error) {
+	var fields []printedField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if o.HideUnexported && !f.IsExported() {
+			continue
+		}
+		tag, err := parseTag(t, f)
+		if err != nil {
+			return nil, err
+		}
+		if !tag.skip {
+			fields = append(fields, printedField{index: i, fieldTag: tag})
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,68 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+type DBConfig struct {
+	Host     string
+	Port     int    `print:"name=port"`
+	User     string `print:"name=user,redact"`
+	Password string `print:"redact"`
+	Cache    []byte `print:"-"`
+	dsn      string
+}
+
+func TestTags(t *testing.T) {
+	cfg := DBConfig{Host: "db", Port: 5432, User: "admin", Password: "s3cret", Cache: []byte("x"), dsn: "postgres://admin:s3cret@db"}
+
+	got, err := Sprint(cfg, Options{HideUnexported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `printer.DBConfig{
+  Host: "db"
+  port: 5432
+  user: <redacted>
+  Password: <redacted>
+}
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "s3cret") {
+		t.Error("secret leaked into output")
+	}
+
+	got, err = Sprint(&cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "\n  dsn: \"postgres://admin:s3cret@db\"\n") {
+		t.Errorf("unexported field not shown by default:\n%s", got)
+	}
+	if strings.Contains(got, "Cache") {
+		t.Errorf("field tagged print:\"-\" was printed:\n%s", got)
+	}
+}
+
+func TestBadTags(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{struct {
+			A int `print:"shout"`
+		}{}, `unknown print tag option "shout"`},
+		{struct {
+			A int `print:"name="`
+		}{}, "empty name"},
+	}
+	for _, tt := range tests {
+		_, err := Sprint(tt.v, Options{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Sprint(%T) error = %v, want %q", tt.v, err, tt.want)
+		}
+	}
+}